@@ -0,0 +1,170 @@
+package gocube
+
+import "strings"
+
+// An Algorithm is a sequence of turns.
+type Algorithm []Turn
+
+// NewAlgorithm creates an Algorithm from a list of face moves.
+func NewAlgorithm(moves []Move) Algorithm {
+	res := make(Algorithm, len(moves))
+	for i, m := range moves {
+		res[i] = MoveTurn(m)
+	}
+	return res
+}
+
+// Apply applies the algorithm to a cube whose centers have a given
+// orientation, updating the orientation as the centers move.
+//
+// The CubieCube always describes the pieces relative to the standard
+// orientation, so turns are translated through the orientation before they
+// are applied.
+func (a Algorithm) Apply(c *CubieCube, o *Orientation) {
+	for _, t := range a {
+		for _, m := range t.Moves() {
+			c.Move(o.Move(m))
+		}
+		if r, ok := t.Rotation(); ok {
+			o.Rotate(r)
+		}
+	}
+}
+
+// Moves flattens the algorithm into face moves relative to the standard
+// orientation. It also returns the orientation of the centers after the
+// algorithm, which is needed to account for slices, wide turns and rotations.
+func (a Algorithm) Moves() ([]Move, Orientation) {
+	orientation := StandardOrientation()
+	var res []Move
+	for _, t := range a {
+		for _, m := range t.Moves() {
+			res = append(res, orientation.Move(m))
+		}
+		if r, ok := t.Rotation(); ok {
+			orientation.Rotate(r)
+		}
+	}
+	return res, orientation
+}
+
+// String returns the algorithm in canonical WCA notation, with turns separated
+// by single spaces. For example, a counter-clockwise turn of R is always
+// written as R', never as R3.
+func (a Algorithm) String() string {
+	strs := make([]string, len(a))
+	for i, t := range a {
+		strs[i] = t.String()
+	}
+	return strings.Join(strs, " ")
+}
+
+// Inverse returns the algorithm which undoes a.
+func (a Algorithm) Inverse() Algorithm {
+	res := make(Algorithm, len(a))
+	for i, t := range a {
+		res[len(a)-(i+1)] = t.Inverse()
+	}
+	return res
+}
+
+// Mirror reflects the algorithm across the plane perpendicular to an axis
+// (0=x, 1=y, 2=z). Mirroring across the x axis (the M plane) turns a right-hand
+// algorithm into the corresponding left-hand one.
+func (a Algorithm) Mirror(axis int) Algorithm {
+	res := make(Algorithm, len(a))
+	for i, t := range a {
+		res[i] = t.Mirror(axis)
+	}
+	return res
+}
+
+// Rotate returns the algorithm which has the same effect as performing the
+// rotation r, then a, then the inverse of r.
+//
+// For example, rotating U by x gives F, since "x U x'" is equivalent to F.
+func (a Algorithm) Rotate(r Rotation) Algorithm {
+	o := StandardOrientation()
+	o.Rotate(r)
+	res := make(Algorithm, len(a))
+	for i, t := range a {
+		res[i] = NewTurn(t.Kind, o[t.Face-1], t.Turns)
+	}
+	return res
+}
+
+// Simplify returns an equivalent algorithm in which no two turns of the same
+// kind and face can be combined.
+//
+// Since turns around the same axis commute, turns are combined even when other
+// turns on that axis lie between them. For example, "U D U'" becomes "D" and
+// "R L R" becomes "R2 L".
+func (a Algorithm) Simplify() Algorithm {
+	res := Algorithm{}
+	for _, t := range a {
+		merged := false
+		for i := len(res) - 1; i >= 0 && res[i].Axis() == t.Axis(); i-- {
+			if res[i].Kind != t.Kind || res[i].Face != t.Face {
+				continue
+			}
+			if turns := (res[i].Turns + t.Turns + 4) % 4; turns == 0 {
+				res = append(res[:i], res[i+1:]...)
+			} else {
+				res[i].Turns = []int{0, 1, 2, -1}[turns]
+			}
+			merged = true
+			break
+		}
+		if !merged {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// HTM returns the length of the algorithm in the half turn metric.
+//
+// Face turns and wide turns count as one move, slices count as two moves, and
+// rotations are free.
+func (a Algorithm) HTM() int {
+	var res int
+	for _, t := range a {
+		switch t.Kind {
+		case FaceTurn, WideTurn:
+			res++
+		case SliceTurn:
+			res += 2
+		}
+	}
+	return res
+}
+
+// QTM returns the length of the algorithm in the quarter turn metric.
+//
+// This is like HTM, except that half turns count twice.
+func (a Algorithm) QTM() int {
+	var res int
+	for _, t := range a {
+		res += t.QuarterTurns()
+	}
+	return res
+}
+
+// STM returns the length of the algorithm in the slice turn metric, where
+// face turns, wide turns and slices each count as one move and rotations are
+// free.
+func (a Algorithm) STM() int {
+	var res int
+	for _, t := range a {
+		if t.Kind != RotationTurn {
+			res++
+		}
+	}
+	return res
+}
+
+// ETM returns the length of the algorithm in the execution turn metric, where
+// every turn, including rotations, counts as one move.
+func (a Algorithm) ETM() int {
+	return len(a)
+}
@@ -0,0 +1,171 @@
+package gocube
+
+import "testing"
+
+func TestAlgorithmEquivalences(t *testing.T) {
+	equivalences := [][2]string{
+		{"x", "R M' L'"},
+		{"y", "U E' D'"},
+		{"z", "F S B'"},
+		{"x2 y'", "R2 M2 L2 U' E D"},
+		{"Rw", "R M'"},
+		{"Uw'", "U' E"},
+		{"Fw2", "F2 S2"},
+		{"Lw", "L M"},
+		{"Dw", "D E"},
+		{"Bw'", "B' S"},
+		{"x U x'", "F"},
+		{"y R y'", "B"},
+		{"z2 F", "F z2"},
+	}
+	for _, e := range equivalences {
+		cube1, orientation1 := applyAlgorithm(t, e[0])
+		cube2, orientation2 := applyAlgorithm(t, e[1])
+		if cube1 != cube2 || orientation1 != orientation2 {
+			t.Errorf("%q is not equivalent to %q", e[0], e[1])
+		}
+	}
+}
+
+func TestAlgorithmMoves(t *testing.T) {
+	algs := []string{
+		"x U",
+		"r U R' U' r' F R F'",
+		"M' U M' U M' U2 M U M U M U2",
+		"y' Lw2 E2 S' 3Rw z 2U x' Dw",
+	}
+	for _, s := range algs {
+		cube, orientation := applyAlgorithm(t, s)
+		alg, _ := ParseAlgorithm(s)
+		moves, moveOrientation := alg.Moves()
+		if moveOrientation != orientation {
+			t.Errorf("%q: orientation %v (expected %v)", s, moveOrientation,
+				orientation)
+		}
+		actual := SolvedCubieCube()
+		for _, m := range moves {
+			actual.Move(m)
+		}
+		if actual != cube {
+			t.Errorf("%q: moves %v give a different state", s, moves)
+		}
+	}
+
+	alg, _ := ParseAlgorithm("x U")
+	moves, _ := alg.Moves()
+	if len(moves) != 1 || moves[0].String() != "F" {
+		t.Errorf("unexpected moves for x U: %v", moves)
+	}
+}
+
+func applyAlgorithm(t *testing.T, s string) (CubieCube, Orientation) {
+	alg, err := ParseAlgorithm(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube := SolvedCubieCube()
+	orientation := StandardOrientation()
+	alg.Apply(&cube, &orientation)
+	return cube, orientation
+}
+
+func TestAlgorithmInverse(t *testing.T) {
+	alg, _ := ParseAlgorithm("R U2 x' M E' Rw2 S z")
+	cube := SolvedCubieCube()
+	orientation := StandardOrientation()
+	alg.Apply(&cube, &orientation)
+	alg.Inverse().Apply(&cube, &orientation)
+	if !cube.Solved() || orientation != StandardOrientation() {
+		t.Error("inverse does not undo the algorithm")
+	}
+	if s := alg.Inverse().String(); s != "z' S' Rw2 E M' x U2 R'" {
+		t.Errorf("unexpected inverse: %s", s)
+	}
+}
+
+func TestAlgorithmMirror(t *testing.T) {
+	cases := []struct {
+		alg      string
+		axis     int
+		expected string
+	}{
+		{"R U R' U'", 0, "L' U' L U"},
+		{"R U R' U'", 1, "R' D' R D"},
+		{"R U R' U'", 2, "R' U' R U"},
+		{"Rw M x E' y S z", 0, "Lw' M x E y' S' z'"},
+	}
+	for _, c := range cases {
+		alg, _ := ParseAlgorithm(c.alg)
+		if s := alg.Mirror(c.axis).String(); s != c.expected {
+			t.Errorf("mirroring %q across %d gave %q (expected %q)", c.alg,
+				c.axis, s, c.expected)
+		}
+		if s := alg.Mirror(c.axis).Mirror(c.axis).String(); s != c.alg {
+			t.Errorf("mirroring %q twice gave %q", c.alg, s)
+		}
+	}
+}
+
+func TestAlgorithmRotate(t *testing.T) {
+	alg, _ := ParseAlgorithm("R U' F2 Dw M' B L2 x S E2 z'")
+	for r := Rotation(0); r < 9; r++ {
+		rotated := alg.Rotate(r)
+		cube1, o1 := applyAlgorithm(t, r.String()+" "+alg.String()+" "+
+			r.Inverse().String())
+		cube2, o2 := applyAlgorithm(t, rotated.String())
+		if cube1 != cube2 || o1 != o2 {
+			t.Errorf("rotation %s: unexpected result %s", r, rotated)
+		}
+	}
+}
+
+func TestAlgorithmSimplify(t *testing.T) {
+	cases := map[string]string{
+		"U D U'":              "D",
+		"R L R":               "R2 L",
+		"R U U' R'":           "",
+		"R U2 U2 R":           "R2",
+		"F B F' B' F2":        "F2",
+		"R U R' U'":           "R U R' U'",
+		"R M R' M'":           "",
+		"x R x' L x2":         "R L x2",
+		"U D2 U R U' R D2 D2": "U2 D2 R U' R",
+	}
+	for input, expected := range cases {
+		alg, _ := ParseAlgorithm(input)
+		simple := alg.Simplify()
+		if simple.String() != expected {
+			t.Errorf("simplified %q to %q (expected %q)", input, simple,
+				expected)
+		}
+		cube1, o1 := applyAlgorithm(t, input)
+		cube2 := SolvedCubieCube()
+		o2 := StandardOrientation()
+		simple.Apply(&cube2, &o2)
+		if cube1 != cube2 || o1 != o2 {
+			t.Errorf("simplified %q to non-equivalent %q", input, simple)
+		}
+	}
+}
+
+func TestAlgorithmMetrics(t *testing.T) {
+	cases := []struct {
+		alg string
+		htm int
+		qtm int
+		stm int
+		etm int
+	}{
+		{"", 0, 0, 0, 0},
+		{"R U2 R'", 3, 4, 3, 3},
+		{"M2 U M2 U2 M2 U M2", 11, 20, 7, 7},
+		{"x Rw U' y2 E", 4, 4, 3, 5},
+	}
+	for _, c := range cases {
+		alg, _ := ParseAlgorithm(c.alg)
+		actual := [4]int{alg.HTM(), alg.QTM(), alg.STM(), alg.ETM()}
+		if actual != [4]int{c.htm, c.qtm, c.stm, c.etm} {
+			t.Errorf("%q: got metrics %v", c.alg, actual)
+		}
+	}
+}
@@ -1,10 +1,5 @@
 package gocube
 
-import (
-	"errors"
-	"strconv"
-)
-
 // CornerIndexes contains 8 sets of 3 values which corresponds to the x, y, and
 // z sticker indexes for each corner piece.
 var CornerIndexes = []int{
@@ -83,36 +78,21 @@ func (c *CubieCube) StickerCube() StickerCube {
 
 	// Insert the corner pieces.
 	for i, piece := range c.Corners {
-		idx := piece.Piece * 3
-		s1, s2, s3 := CornerPieces[idx], CornerPieces[idx+1],
-			CornerPieces[idx+2]
-
-		// Transform corner piece to move to its current position.
-		// If an odd number of quarter turns were needed to move it to this
-		// position, the corner's permutation is in the odd-parity coset.
-		difference := (piece.Piece ^ i) & 7
-		if difference == 1 || difference == 2 || difference == 4 ||
-			difference == 7 {
-			s1, s3 = s3, s1
-		}
-
-		// Twist the corner piece
-		if piece.Orientation == 2 {
-			s1, s2, s3 = s3, s1, s2
-		} else if piece.Orientation == 0 {
-			s1, s2, s3 = s2, s3, s1
-		}
-
+		stickers := cornerStickers(i, piece)
 		destIdx := i * 3
-		res[CornerIndexes[destIdx]] = s1
-		res[CornerIndexes[destIdx+1]] = s2
-		res[CornerIndexes[destIdx+2]] = s3
+		res[CornerIndexes[destIdx]] = stickers[0]
+		res[CornerIndexes[destIdx+1]] = stickers[1]
+		res[CornerIndexes[destIdx+2]] = stickers[2]
 	}
 
 	return res
 }
 
 // CubieCube converts a StickerCube to a CubieCube.
+//
+// If the stickers in a slot do not belong to any piece, a *StickerError is
+// returned. The resulting cube is not validated further; use Validate for
+// that.
 func (s *StickerCube) CubieCube() (*CubieCube, error) {
 	var result CubieCube
 
@@ -121,7 +101,7 @@ func (s *StickerCube) CubieCube() (*CubieCube, error) {
 		idx := i * 3
 		stickers := [3]int{s[CornerIndexes[idx]], s[CornerIndexes[idx+1]],
 			s[CornerIndexes[idx+2]]}
-		piece, orientation, err := findCorner(stickers)
+		piece, orientation, err := findCorner(i, stickers)
 		if err != nil {
 			return nil, err
 		}
@@ -133,7 +113,7 @@ func (s *StickerCube) CubieCube() (*CubieCube, error) {
 	for i := 0; i < 12; i++ {
 		idx := i * 2
 		stickers := [2]int{s[EdgeIndexes[idx]], s[EdgeIndexes[idx+1]]}
-		piece, flip, err := findEdge(stickers)
+		piece, flip, err := findEdge(i, stickers)
 		if err != nil {
 			return nil, err
 		}
@@ -144,8 +124,9 @@ func (s *StickerCube) CubieCube() (*CubieCube, error) {
 	return &result, nil
 }
 
-// findCorner finds the physical corner given its three colors.
-func findCorner(stickers [3]int) (idx int, orientation int, err error) {
+// findCorner finds the physical corner given its three colors in a slot.
+func findCorner(slot int, stickers [3]int) (idx int, orientation int,
+	err error) {
 	for i := 0; i < 8; i++ {
 		start := i * 3
 		if !setsEqual(stickers[:], CornerPieces[start:start+3]) {
@@ -155,15 +136,48 @@ func findCorner(stickers [3]int) (idx int, orientation int, err error) {
 		if orientation == -1 {
 			orientation = listIndex(stickers[:], 2)
 		}
+
+		// A mirrored corner has the right colors in the wrong order.
+		if cornerStickers(slot, CubieCorner{i, orientation}) != stickers {
+			break
+		}
 		return i, orientation, nil
 	}
-	return 0, 0, errors.New("unrecognized corner: " +
-		strconv.Itoa(stickers[0]) + "," + strconv.Itoa(stickers[1]) + "," +
-		strconv.Itoa(stickers[2]))
+	// Report the stickers in the same order as the slot's name.
+	var named []int
+	for _, face := range CornerNames[slot] {
+		named = append(named, stickers[faceAxis(face)])
+	}
+	return 0, 0, &StickerError{CornerKind, slot, named}
+}
+
+// cornerStickers returns the x, y, and z stickers of a corner piece when it is
+// placed in a given slot.
+func cornerStickers(slot int, piece CubieCorner) [3]int {
+	idx := piece.Piece * 3
+	s1, s2, s3 := CornerPieces[idx], CornerPieces[idx+1], CornerPieces[idx+2]
+
+	// Transform corner piece to move to its current position.
+	// If an odd number of quarter turns were needed to move it to this
+	// position, the corner's permutation is in the odd-parity coset.
+	difference := (piece.Piece ^ slot) & 7
+	if difference == 1 || difference == 2 || difference == 4 ||
+		difference == 7 {
+		s1, s3 = s3, s1
+	}
+
+	// Twist the corner piece
+	if piece.Orientation == 2 {
+		s1, s2, s3 = s3, s1, s2
+	} else if piece.Orientation == 0 {
+		s1, s2, s3 = s2, s3, s1
+	}
+
+	return [3]int{s1, s2, s3}
 }
 
-// findEdge finds the physical edge given its two colors.
-func findEdge(stickers [2]int) (idx int, flip bool, err error) {
+// findEdge finds the physical edge given its two colors in a slot.
+func findEdge(slot int, stickers [2]int) (idx int, flip bool, err error) {
 	for i := 0; i < 12; i++ {
 		start := i * 2
 		if !setsEqual(stickers[:], EdgePieces[start:start+2]) {
@@ -183,8 +197,20 @@ func findEdge(stickers [2]int) (idx int, flip bool, err error) {
 		}
 		return i, flip, nil
 	}
-	return 0, false, errors.New("unrecognized edge: " +
-		strconv.Itoa(stickers[0]) + "," + strconv.Itoa(stickers[1]))
+	return 0, false, &StickerError{EdgeKind, slot, stickers[:]}
+}
+
+// faceAxis returns the axis (0, 1, or 2 for x, y, or z) normal to a face,
+// given the face's letter.
+func faceAxis(face rune) int {
+	switch face {
+	case 'R', 'L':
+		return 0
+	case 'U', 'D':
+		return 1
+	default:
+		return 2
+	}
 }
 
 func listContains(list []int, num int) bool {
@@ -214,5 +240,10 @@ func setsEqual(set1 []int, set2 []int) bool {
 			return false
 		}
 	}
+	for _, x := range set2 {
+		if !listContains(set1, x) {
+			return false
+		}
+	}
 	return true
 }
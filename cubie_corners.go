@@ -24,6 +24,34 @@ type CubieCorner struct {
 	Orientation int
 }
 
+// These are the indices of the corner slots.
+const (
+	CornerDLB = iota
+	CornerDBR
+	CornerUBL
+	CornerURB
+	CornerDFL
+	CornerDRF
+	CornerULF
+	CornerUFR
+)
+
+// CornerNames contains the name of each corner slot, indexed by corner index.
+var CornerNames = []string{"DLB", "DBR", "UBL", "URB", "DFL", "DRF", "ULF",
+	"UFR"}
+
+// A CornerCycle is a cycle in the permutation of a set of corners.
+type CornerCycle struct {
+	// Slots lists the slots in the cycle. The piece in each slot is moved to
+	// the next slot in the list, and the piece in the last slot is moved to
+	// the first slot.
+	Slots []int
+
+	// Twist is the total number of clockwise twists (0, 1, or 2) which the
+	// pieces of the cycle have accumulated.
+	Twist int
+}
+
 // CubieCorners represents the corners of a cube.
 type CubieCorners [8]CubieCorner
 
@@ -171,6 +199,81 @@ func (c *CubieCorners) Solved() bool {
 	return true
 }
 
+// Twist returns the number of clockwise twists (0, 1, or 2) of the corner in a
+// given slot, measured relative to the slot's y axis.
+//
+// Unlike the Orientation field, this value does not depend on the handedness of
+// the slot, so twists can be added together as pieces move around the cube.
+func (c *CubieCorners) Twist(slot int) int {
+	return cornerTwist(slot, c[slot].Orientation)
+}
+
+// Multiply returns the corners which result from applying the permutation of d
+// to c.
+//
+// If c and d are the results of applying move sequences A and B to solved
+// corners, the result is equivalent to applying A and then B.
+func (c *CubieCorners) Multiply(d *CubieCorners) CubieCorners {
+	var res CubieCorners
+	for i, corner := range d {
+		source := corner.Piece
+		twist := (c.Twist(source) + d.Twist(i)) % 3
+		res[i].Piece = c[source].Piece
+		res[i].Orientation = cornerOrientation(i, twist)
+	}
+	return res
+}
+
+// Inverse returns the corners which undo the permutation of c.
+func (c *CubieCorners) Inverse() CubieCorners {
+	var res CubieCorners
+	for i, corner := range c {
+		twist := (3 - c.Twist(i)) % 3
+		res[corner.Piece].Piece = i
+		res[corner.Piece].Orientation = cornerOrientation(corner.Piece, twist)
+	}
+	return res
+}
+
+// Parity returns true if the corner permutation is even.
+func (c *CubieCorners) Parity() bool {
+	perm := make([]int, 8)
+	for i, corner := range c {
+		perm[i] = corner.Piece
+	}
+	return parity(perm)
+}
+
+// Cycles decomposes the corners into disjoint cycles.
+//
+// Corners which are solved are not included. Corners which are in their home
+// slot but twisted are reported as cycles of length 1.
+func (c *CubieCorners) Cycles() []CornerCycle {
+	var destinations [8]int
+	for i, corner := range c {
+		destinations[corner.Piece] = i
+	}
+
+	var res []CornerCycle
+	var visited [8]bool
+	for start := 0; start < 8; start++ {
+		if visited[start] {
+			continue
+		}
+		var cycle CornerCycle
+		for slot := start; !visited[slot]; slot = destinations[slot] {
+			visited[slot] = true
+			cycle.Slots = append(cycle.Slots, slot)
+			cycle.Twist += c.Twist(slot)
+		}
+		cycle.Twist %= 3
+		if len(cycle.Slots) > 1 || cycle.Twist != 0 {
+			res = append(res, cycle)
+		}
+	}
+	return res
+}
+
 // EncodeIndex encodes the state of the corners as a unique integer in the
 // range [0, 3^7 * 8!).
 //
@@ -222,3 +325,31 @@ func (c *CubieCorners) fixLastOrientation() {
 		c[7].Orientation = 0
 	}
 }
+
+// isOddCornerSlot returns true if a corner slot is an odd number of quarter
+// turns away from corner 0. The x, y, and z axes are arranged clockwise around
+// even slots and counter-clockwise around odd slots.
+func isOddCornerSlot(slot int) bool {
+	return slot == 1 || slot == 2 || slot == 4 || slot == 7
+}
+
+// cornerTwist converts an orientation in a given slot to a clockwise twist.
+func cornerTwist(slot, orientation int) int {
+	if orientation == 1 {
+		return 0
+	} else if (orientation == 2) != isOddCornerSlot(slot) {
+		return 1
+	}
+	return 2
+}
+
+// cornerOrientation converts a clockwise twist in a given slot to an
+// orientation.
+func cornerOrientation(slot, twist int) int {
+	if twist == 0 {
+		return 1
+	} else if (twist == 1) != isOddCornerSlot(slot) {
+		return 2
+	}
+	return 0
+}
@@ -64,3 +64,61 @@ func TestCubieCornersFixLastOrientation(t *testing.T) {
 		}
 	}
 }
+
+func TestCubieCornersCycles(t *testing.T) {
+	corners := SolvedCubieCorners()
+	corners.Move(NewMove(1, 1))
+	cycles := corners.Cycles()
+	if len(cycles) != 1 || len(cycles[0].Slots) != 4 || cycles[0].Twist != 0 {
+		t.Fatal("unexpected cycles for U:", cycles)
+	}
+	for i, slot := range cycles[0].Slots {
+		next := cycles[0].Slots[(i+1)%4]
+		if corners[next].Piece != slot {
+			t.Errorf("piece from slot %d should be in slot %d", slot, next)
+		}
+	}
+
+	// A sune followed by a mirrored anti-sune twists two corners in place.
+	moves, _ := ParseMoves("R U R' U R U2 R' U2 L' U' L U' L' U2 L U2")
+	corners = SolvedCubieCorners()
+	for _, m := range moves {
+		corners.Move(m)
+	}
+	cycles = corners.Cycles()
+	if len(cycles) != 2 {
+		t.Fatal("unexpected cycles:", cycles)
+	}
+	for _, cycle := range cycles {
+		if len(cycle.Slots) != 1 || cycle.Twist == 0 {
+			t.Fatal("unexpected cycles:", cycles)
+		}
+	}
+	if (cycles[0].Twist+cycles[1].Twist)%3 != 0 {
+		t.Error("unexpected twists:", cycles)
+	}
+}
+
+func TestCubieCornersTwist(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		corners := RandomCubieCube().Corners
+		sum := 0
+		for slot := 0; slot < 8; slot++ {
+			sum += corners.Twist(slot)
+			twist := corners.Twist(slot)
+			if cornerOrientation(slot, twist) != corners[slot].Orientation {
+				t.Fatal("twist conversion is not invertible")
+			}
+		}
+		if sum%3 != 0 {
+			t.Fatal("invalid twist sum:", sum)
+		}
+		cycleSum := 0
+		for _, cycle := range corners.Cycles() {
+			cycleSum += cycle.Twist
+		}
+		if cycleSum%3 != 0 {
+			t.Fatal("invalid cycle twist sum:", cycleSum)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package gocube
 
 // A CubieCube represents a cube's physical construction.
+//
+// CubieCubes form a group under Multiply. Two CubieCubes can be compared with
+// the == operator.
 type CubieCube struct {
 	Corners CubieCorners
 	Edges   CubieEdges
@@ -11,6 +14,11 @@ func SolvedCubieCube() CubieCube {
 	return CubieCube{SolvedCubieCorners(), SolvedCubieEdges()}
 }
 
+// Equal returns true if two cubes are in the same state.
+func (c *CubieCube) Equal(d *CubieCube) bool {
+	return *c == *d
+}
+
 // HalfTurn applies a half-turn to the edges and corners.
 func (c *CubieCube) HalfTurn(face int) {
 	c.Corners.HalfTurn(face)
@@ -33,3 +41,47 @@ func (c *CubieCube) QuarterTurn(face, turns int) {
 func (c *CubieCube) Solved() bool {
 	return c.Corners.Solved() && c.Edges.Solved()
 }
+
+// Multiply returns the cube which results from applying the permutation of d
+// to c.
+//
+// If c and d are the results of applying move sequences A and B to a solved
+// cube, the result is equivalent to applying A and then B. Thus, the state
+// which takes c to d is c.Inverse().Multiply(d).
+func (c *CubieCube) Multiply(d *CubieCube) CubieCube {
+	return CubieCube{c.Corners.Multiply(&d.Corners), c.Edges.Multiply(&d.Edges)}
+}
+
+// Inverse returns the cube which undoes the permutation of c.
+func (c *CubieCube) Inverse() CubieCube {
+	return CubieCube{c.Corners.Inverse(), c.Edges.Inverse()}
+}
+
+// Order returns the number of times c must be applied to a solved cube before
+// the cube returns to the solved state.
+func (c *CubieCube) Order() int {
+	res := 1
+	for _, cycle := range c.Corners.Cycles() {
+		length := len(cycle.Slots)
+		if cycle.Twist != 0 {
+			length *= 3
+		}
+		res = lcm(res, length)
+	}
+	for _, cycle := range c.Edges.Cycles() {
+		length := len(cycle.Slots)
+		if cycle.Flip {
+			length *= 2
+		}
+		res = lcm(res, length)
+	}
+	return res
+}
+
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
@@ -1,6 +1,7 @@
 package gocube
 
 import (
+	"math/rand"
 	"testing"
 )
 
@@ -33,3 +34,80 @@ func BenchmarkCubieQuarterTurn(b *testing.B) {
 		}
 	}
 }
+
+func TestCubieCubeMultiply(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		first := RandomCubieCube()
+		second := SolvedCubieCube()
+		expected := first
+		for j := 0; j < 30; j++ {
+			m := Move(rand.Intn(18))
+			second.Move(m)
+			expected.Move(m)
+		}
+		if actual := first.Multiply(&second); actual != expected {
+			t.Errorf("unexpected product %v (expected %v)", actual, expected)
+		}
+	}
+}
+
+func TestCubieCubeInverse(t *testing.T) {
+	moves, _ := ParseMoves("B U D B' L2 D' R' F2 L F D2 R2 F' U2 R B2 L' U'")
+	cube := SolvedCubieCube()
+	for _, m := range moves {
+		cube.Move(m)
+	}
+	expected := SolvedCubieCube()
+	for i := len(moves) - 1; i >= 0; i-- {
+		expected.Move(moves[i].Inverse())
+	}
+	inverse := cube.Inverse()
+	if inverse != expected {
+		t.Error("unexpected inverse:", inverse)
+	}
+	if product := cube.Multiply(&inverse); !product.Solved() {
+		t.Error("product with inverse is not solved:", product)
+	}
+	if product := inverse.Multiply(&cube); !product.Solved() {
+		t.Error("product with inverse is not solved:", product)
+	}
+}
+
+func TestCubieCubeOrder(t *testing.T) {
+	orders := map[string]int{
+		"U":              4,
+		"U2":             2,
+		"R U":            105,
+		"R U R' U'":      6,
+		"R U2 D' B D'":   1260,
+		"R2 U2 R2 U2":    3,
+		"F R U R' U' F'": 6,
+	}
+	for algorithm, expected := range orders {
+		moves, _ := ParseMoves(algorithm)
+		cube := SolvedCubieCube()
+		for _, m := range moves {
+			cube.Move(m)
+		}
+		if order := cube.Order(); order != expected {
+			t.Errorf("expected order %d for %s but got %d", expected,
+				algorithm, order)
+			continue
+		}
+		power := cube
+		for i := 1; i < expected; i++ {
+			if power.Solved() {
+				t.Errorf("%s solved after only %d applications", algorithm, i)
+				break
+			}
+			power = power.Multiply(&cube)
+		}
+		if !power.Solved() {
+			t.Errorf("%s not solved after %d applications", algorithm, expected)
+		}
+	}
+	solved := SolvedCubieCube()
+	if solved.Order() != 1 {
+		t.Error("invalid order for solved cube:", solved.Order())
+	}
+}
@@ -12,6 +12,38 @@ type CubieEdge struct {
 	Flip  bool
 }
 
+// These are the indices of the edge slots.
+const (
+	EdgeUF = iota
+	EdgeFR
+	EdgeDF
+	EdgeFL
+	EdgeUL
+	EdgeUR
+	EdgeUB
+	EdgeBR
+	EdgeDB
+	EdgeBL
+	EdgeDL
+	EdgeDR
+)
+
+// EdgeNames contains the name of each edge slot, indexed by edge index.
+var EdgeNames = []string{"UF", "FR", "DF", "FL", "UL", "UR", "UB", "BR", "DB",
+	"BL", "DL", "DR"}
+
+// An EdgeCycle is a cycle in the permutation of a set of edges.
+type EdgeCycle struct {
+	// Slots lists the slots in the cycle. The piece in each slot is moved to
+	// the next slot in the list, and the piece in the last slot is moved to
+	// the first slot.
+	Slots []int
+
+	// Flip is true if the pieces of the cycle have accumulated an odd number
+	// of flips.
+	Flip bool
+}
+
 // CubieEdges represents the edges of a cube.
 type CubieEdges [12]CubieEdge
 
@@ -124,6 +156,68 @@ func (c *CubieEdges) Solved() bool {
 	return true
 }
 
+// Multiply returns the edges which result from applying the permutation of d
+// to c.
+//
+// If c and d are the results of applying move sequences A and B to solved
+// edges, the result is equivalent to applying A and then B.
+func (c *CubieEdges) Multiply(d *CubieEdges) CubieEdges {
+	var res CubieEdges
+	for i, edge := range d {
+		res[i].Piece = c[edge.Piece].Piece
+		res[i].Flip = c[edge.Piece].Flip != edge.Flip
+	}
+	return res
+}
+
+// Inverse returns the edges which undo the permutation of c.
+func (c *CubieEdges) Inverse() CubieEdges {
+	var res CubieEdges
+	for i, edge := range c {
+		res[edge.Piece].Piece = i
+		res[edge.Piece].Flip = edge.Flip
+	}
+	return res
+}
+
+// Parity returns true if the edge permutation is even.
+func (c *CubieEdges) Parity() bool {
+	perm := make([]int, 12)
+	for i, edge := range c {
+		perm[i] = edge.Piece
+	}
+	return parity(perm)
+}
+
+// Cycles decomposes the edges into disjoint cycles.
+//
+// Edges which are solved are not included. Edges which are in their home slot
+// but flipped are reported as cycles of length 1.
+func (c *CubieEdges) Cycles() []EdgeCycle {
+	var destinations [12]int
+	for i, edge := range c {
+		destinations[edge.Piece] = i
+	}
+
+	var res []EdgeCycle
+	var visited [12]bool
+	for start := 0; start < 12; start++ {
+		if visited[start] {
+			continue
+		}
+		var cycle EdgeCycle
+		for slot := start; !visited[slot]; slot = destinations[slot] {
+			visited[slot] = true
+			cycle.Slots = append(cycle.Slots, slot)
+			cycle.Flip = cycle.Flip != c[slot].Flip
+		}
+		if len(cycle.Slots) > 1 || cycle.Flip {
+			res = append(res, cycle)
+		}
+	}
+	return res
+}
+
 // EncodeIndex encodes the state of the edges into an integer.
 //
 // If includeParity is true, then the state space is twice as large and
@@ -40,3 +40,35 @@ func TestCubieEdges(t *testing.T) {
 		}
 	}
 }
+
+func TestCubieEdgesCycles(t *testing.T) {
+	moves, _ := ParseMoves("F")
+	edges := SolvedCubieEdges()
+	edges.Move(moves[0])
+	cycles := edges.Cycles()
+	if len(cycles) != 1 || len(cycles[0].Slots) != 4 || cycles[0].Flip {
+		t.Fatal("unexpected cycles for F:", cycles)
+	}
+	for i, slot := range cycles[0].Slots {
+		next := cycles[0].Slots[(i+1)%4]
+		if edges[next].Piece != slot {
+			t.Errorf("piece from slot %d should be in slot %d", slot, next)
+		}
+	}
+
+	// The superflip flips every edge in place.
+	moves, _ = ParseMoves("U R2 F B R B2 R U2 L B2 R U' D' R2 F R' L B2 U2 F2")
+	edges = SolvedCubieEdges()
+	for _, m := range moves {
+		edges.Move(m)
+	}
+	cycles = edges.Cycles()
+	if len(cycles) != 12 {
+		t.Fatal("unexpected cycles for superflip:", cycles)
+	}
+	for _, cycle := range cycles {
+		if len(cycle.Slots) != 1 || !cycle.Flip {
+			t.Error("unexpected cycle:", cycle)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/unixpickle/gocube"
 	"github.com/unixpickle/gocube/fmc"
@@ -14,13 +16,29 @@ func main() {
 		fmt.Println("Failed to read stickers:", err)
 		os.Exit(1)
 	}
-	cc, err := sc.CubieCube()
-	if err != nil {
+	if err := sc.Validate(); err != nil {
 		fmt.Println("Invalid stickers:", err)
 		os.Exit(1)
 	}
-	solutions := fmc.ThreeStepF2LMinus1(*cc)
+	cc, _ := sc.CubieCube()
+	observer := &gocube.SearchObserver{}
+	go printProgress(observer)
+	solutions := fmc.ThreeStepF2LMinus1Context(context.Background(), *cc,
+		observer)
 	for solution := range solutions {
-		fmt.Println("Solution (", len(solution), "): ", solution)
+		alg := gocube.NewAlgorithm(solution)
+		fmt.Println("Solution (", alg.HTM(), "): ", alg)
+	}
+}
+
+// printProgress prints the statistics for each step of the search every few
+// seconds.
+func printProgress(observer *gocube.SearchObserver) {
+	for range time.Tick(5 * time.Second) {
+		for i, stats := range observer.Stats() {
+			fmt.Fprintf(os.Stderr, "Step %d: depth %d, %d nodes, %d prunes, "+
+				"%d searches, %v\n", i+1, stats.Depth, stats.Nodes,
+				stats.Prunes, stats.Searches, stats.Time.Round(time.Millisecond))
+		}
 	}
 }
@@ -0,0 +1,144 @@
+package gocube
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KociembaCornerIndices maps the standard corner numbering used by Kociemba's
+// solver (URF, UFL, ULB, UBR, DFR, DLF, DBL, DRB) to gocube corner indices.
+var KociembaCornerIndices = []int{7, 6, 2, 3, 5, 4, 0, 1}
+
+// KociembaEdgeIndices maps the standard edge numbering used by Kociemba's
+// solver (UR, UF, UL, UB, DR, DF, DL, DB, FR, FL, BL, BR) to gocube edge
+// indices.
+var KociembaEdgeIndices = []int{5, 0, 4, 6, 11, 2, 10, 8, 1, 3, 9, 7}
+
+// faceletFaces lists the faces in the order they appear in a facelet string.
+var faceletFaces = []int{1, 5, 3, 2, 6, 4}
+
+// ParseFaceletString parses a 54-character facelet string in the format used
+// by Kociemba's solver and many other tools.
+//
+// The string lists the faces in the order U, R, F, D, L, B. Each face is read
+// from left to right and top to bottom, using the same reading order as a
+// StickerCube. Each facelet is named by the face whose center has its color.
+//
+// If the string is malformed, the returned error is a *FaceletError. The
+// result is also checked with StickerCube.Validate, and any error from it is
+// returned.
+func ParseFaceletString(str string) (*StickerCube, error) {
+	if len(str) != 54 {
+		return nil, &FaceletError{Length: len(str), Index: -1}
+	}
+	var res StickerCube
+	for i, face := range faceletFaces {
+		for j := 0; j < 9; j++ {
+			letter := rune(str[i*9+j])
+			color := faceNumber(letter)
+			if color == 0 {
+				return nil, &FaceletError{Length: len(str), Index: i*9 + j,
+					Char: letter}
+			}
+			res[(face-1)*9+j] = color
+		}
+	}
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// A FaceletError indicates that a facelet string has the wrong length or
+// contains a character which does not name a face.
+type FaceletError struct {
+	// Length is the length of the string.
+	Length int
+
+	// Index is the index of the unexpected character, or -1 if the string
+	// has the wrong length.
+	Index int
+	Char  rune
+}
+
+func (f *FaceletError) Error() string {
+	if f.Index < 0 {
+		return fmt.Sprintf("facelet string has %d characters instead of 54",
+			f.Length)
+	}
+	return fmt.Sprintf("unexpected character %q at index %d of facelet string",
+		f.Char, f.Index)
+}
+
+// FaceletString converts the stickers to a facelet string, as described in
+// ParseFaceletString.
+//
+// Each sticker is named by the face whose center has the same color, so the
+// stickers need not use the standard color scheme.
+func (s *StickerCube) FaceletString() string {
+	names := map[int]byte{}
+	for face := 1; face <= 6; face++ {
+		names[s[(face-1)*9+4]] = faceName(face)[0]
+	}
+	var res strings.Builder
+	for _, face := range faceletFaces {
+		for j := 0; j < 9; j++ {
+			res.WriteByte(names[s[(face-1)*9+j]])
+		}
+	}
+	return res.String()
+}
+
+// FaceletString converts the cube to a facelet string, as described in
+// ParseFaceletString.
+func (c *CubieCube) FaceletString() string {
+	stickers := c.StickerCube()
+	return stickers.FaceletString()
+}
+
+// KociembaCubies returns the cubie-level representation of the cube used by
+// Kociemba's solver.
+//
+// Each array is indexed by a position in the Kociemba numbering. The cp and
+// ep arrays give the piece in each position, again in Kociemba numbering. The
+// co array gives the clockwise twist of each corner and the eo array gives 1
+// for each flipped edge.
+func (c *CubieCube) KociembaCubies() (cp, co [8]int, ep, eo [12]int) {
+	var cornerNumbers [8]int
+	for i, idx := range KociembaCornerIndices {
+		cornerNumbers[idx] = i
+	}
+	var edgeNumbers [12]int
+	for i, idx := range KociembaEdgeIndices {
+		edgeNumbers[idx] = i
+	}
+
+	for i, idx := range KociembaCornerIndices {
+		cp[i] = cornerNumbers[c.Corners[idx].Piece]
+		co[i] = c.Corners.Twist(idx)
+	}
+	for i, idx := range KociembaEdgeIndices {
+		ep[i] = edgeNumbers[c.Edges[idx].Piece]
+		if c.Edges[idx].Flip {
+			eo[i] = 1
+		}
+	}
+	return
+}
+
+// NewCubieCubeKociemba creates a CubieCube from the representation returned
+// by KociembaCubies.
+//
+// The result is not validated.
+func NewCubieCubeKociemba(cp, co [8]int, ep, eo [12]int) CubieCube {
+	var res CubieCube
+	for i, idx := range KociembaCornerIndices {
+		res.Corners[idx].Piece = KociembaCornerIndices[cp[i]]
+		res.Corners[idx].Orientation = cornerOrientation(idx, co[i])
+	}
+	for i, idx := range KociembaEdgeIndices {
+		res.Edges[idx].Piece = KociembaEdgeIndices[ep[i]]
+		res.Edges[idx].Flip = eo[i] != 0
+	}
+	return res
+}
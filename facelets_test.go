@@ -0,0 +1,126 @@
+package gocube
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFaceletString(t *testing.T) {
+	cases := map[string]string{
+		"":  "UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBB",
+		"R": "UUFUUFUUFRRRRRRRRRFFDFFDFFDDDBDDBDDBLLLLLLLLLUBBUBBUBB",
+		"U": "UUUUUUUUUBBBRRRRRRRRRFFFFFFDDDDDDDDDFFFLLLLLLLLLBBBBBB",
+		"F": "UUUUUULLLURRURRURRFFFFFFFFFRRRDDDDDDLLDLLDLLDBBBBBBBBB",
+	}
+	for s, expected := range cases {
+		alg, _ := ParseAlgorithm(s)
+		cube, _ := applyAlgorithm(t, alg.String())
+		if actual := cube.FaceletString(); actual != expected {
+			t.Errorf("%q: got %s (expected %s)", s, actual, expected)
+		}
+		stickers, err := ParseFaceletString(expected)
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+			continue
+		}
+		parsed, err := stickers.CubieCube()
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+		} else if *parsed != cube {
+			t.Errorf("%q: facelets did not round trip", s)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		stickers, err := ParseFaceletString(cube.FaceletString())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stickers.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		parsed, _ := stickers.CubieCube()
+		if *parsed != cube {
+			t.Error("random cube did not round trip")
+		}
+	}
+
+	invalid := []string{
+		"UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBB",
+		"UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBX",
+		"UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBU",
+		"UUUURUUUURRRRURRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBB",
+	}
+	for _, s := range invalid {
+		if _, err := ParseFaceletString(s); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+
+	var faceletErr *FaceletError
+	_, err := ParseFaceletString(invalid[1])
+	if !errors.As(err, &faceletErr) || faceletErr.Index != 53 {
+		t.Errorf("unexpected error for bad character: %v", err)
+	}
+	var centerErr *CenterError
+	_, err = ParseFaceletString(invalid[3])
+	if !errors.As(err, &centerErr) || centerErr.Face != 1 {
+		t.Errorf("unexpected error for moved center: %v", err)
+	}
+
+	// This has a twisted corner, so the counts and centers are right but the
+	// state is impossible.
+	twisted := SolvedCubieCube()
+	twisted.Corners[0].Orientation = 0
+	stickers := twisted.StickerCube()
+	_, err = ParseFaceletString(stickers.FaceletString())
+	var twistErr *TwistError
+	if !errors.As(err, &twistErr) {
+		t.Errorf("expected *TwistError but got %v", err)
+	}
+}
+
+func TestKociembaCubies(t *testing.T) {
+	cases := []struct {
+		move string
+		cp   [8]int
+		co   [8]int
+		ep   [12]int
+		eo   [12]int
+	}{
+		{
+			"R",
+			[8]int{4, 1, 2, 0, 7, 5, 6, 3},
+			[8]int{2, 0, 0, 1, 1, 0, 0, 2},
+			[12]int{8, 1, 2, 3, 11, 5, 6, 7, 4, 9, 10, 0},
+			[12]int{},
+		},
+		{
+			"F",
+			[8]int{1, 5, 2, 3, 0, 4, 6, 7},
+			[8]int{1, 2, 0, 0, 2, 1, 0, 0},
+			[12]int{0, 9, 2, 3, 4, 8, 6, 7, 1, 5, 10, 11},
+			[12]int{0, 1, 0, 0, 0, 1, 0, 0, 1, 1, 0, 0},
+		},
+	}
+	for _, c := range cases {
+		move, _ := ParseMove(c.move)
+		cube := SolvedCubieCube()
+		cube.Move(move)
+		cp, co, ep, eo := cube.KociembaCubies()
+		if cp != c.cp || co != c.co || ep != c.ep || eo != c.eo {
+			t.Errorf("%s: got %v %v %v %v", c.move, cp, co, ep, eo)
+		}
+		if NewCubieCubeKociemba(cp, co, ep, eo) != cube {
+			t.Errorf("%s: cubies did not round trip", c.move)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		if NewCubieCubeKociemba(cube.KociembaCubies()) != cube {
+			t.Error("random cube did not round trip")
+		}
+	}
+}
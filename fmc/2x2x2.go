@@ -1,6 +1,10 @@
 package fmc
 
-import "github.com/unixpickle/gocube"
+import (
+	"context"
+
+	"github.com/unixpickle/gocube"
+)
 
 // Is2x2x2Solved returns whether or not any 2x2x2 blocks are solved. If a block
 // is solved, its corresponding corner index is also returned.
@@ -13,14 +17,14 @@ func Is2x2x2Solved(state gocube.CubieCube) (solved bool, cornerIndex int) {
 	}
 
 	edgesForCorners := []int{
-		8, 9, 10,
-		7, 8, 11,
-		4, 6, 9,
-		5, 6, 7,
-		2, 3, 10,
-		1, 2, 11,
-		0, 3, 4,
-		0, 1, 5,
+		gocube.EdgeDB, gocube.EdgeBL, gocube.EdgeDL,
+		gocube.EdgeBR, gocube.EdgeDB, gocube.EdgeDR,
+		gocube.EdgeUL, gocube.EdgeUB, gocube.EdgeBL,
+		gocube.EdgeUR, gocube.EdgeUB, gocube.EdgeBR,
+		gocube.EdgeDF, gocube.EdgeFL, gocube.EdgeDL,
+		gocube.EdgeFR, gocube.EdgeDF, gocube.EdgeDR,
+		gocube.EdgeUF, gocube.EdgeFL, gocube.EdgeUL,
+		gocube.EdgeUF, gocube.EdgeFR, gocube.EdgeUR,
 	}
 
 OuterLoop:
@@ -40,39 +44,19 @@ OuterLoop:
 
 // Solve2x2x2 finds solutions to 2x2x2 blocks.
 func Solve2x2x2(cube gocube.CubieCube) <-chan []gocube.Move {
-	channel := make(chan []gocube.Move, 1)
-	go iterativeDeepening2x2x2(cube, channel)
-	return channel
-}
-
-func iterativeDeepening2x2x2(start gocube.CubieCube,
-	output chan<- []gocube.Move) {
-	for depth := 0; true; depth++ {
-		moves := make([]gocube.Move, 0, depth)
-		solve2x2x2Search(start, depth, 0, moves, output)
-	}
+	return Solve2x2x2Context(context.Background(), cube, nil)
 }
 
-func solve2x2x2Search(start gocube.CubieCube, depth, lastFace int,
-	previousMoves []gocube.Move, output chan<- []gocube.Move) {
-	if depth == 0 {
-		if solved, _ := Is2x2x2Solved(start); solved {
-			newArray := make([]gocube.Move, len(previousMoves))
-			copy(newArray, previousMoves)
-			output <- newArray
-		}
-		return
-	}
-	for m := 0; m < 18; m++ {
-		move := gocube.Move(m)
-		face := move.Face()
-		if face == lastFace {
-			continue
-		}
-		newCube := start
-		newCube.Move(move)
-		previousMoves := append(previousMoves, move)
-		solve2x2x2Search(newCube, depth-1, face, previousMoves, output)
-		previousMoves = previousMoves[:len(previousMoves)-1]
-	}
+// Solve2x2x2Context is like Solve2x2x2, but it stops when ctx is done, and it
+// reports its progress to the Phase2x2x2 phase of an observer, which may be
+// nil.
+func Solve2x2x2Context(ctx context.Context, cube gocube.CubieCube,
+	observer *gocube.SearchObserver) <-chan []gocube.Move {
+	return Search(ctx, cube, SearchOptions{
+		Goal: func(c gocube.CubieCube) bool {
+			solved, _ := Is2x2x2Solved(c)
+			return solved
+		},
+		Observer: observer.Phase(Phase2x2x2),
+	})
 }
@@ -1,6 +1,10 @@
 package fmc
 
-import "github.com/unixpickle/gocube"
+import (
+	"context"
+
+	"github.com/unixpickle/gocube"
+)
 
 // Is2x2x3Solved returns whether or not any 2x2x3 blocks are solved. If a block
 // is solved, its corresponding cross edge index is also returned.
@@ -20,33 +24,33 @@ func Is2x2x3Solved(state gocube.CubieCube) (solved bool, edgeIndex int) {
 	}
 
 	cornersForBlocks := []int{
-		6, 7,
-		5, 7,
-		4, 5,
-		4, 6,
-		2, 6,
-		3, 7,
-		2, 3,
-		1, 3,
-		0, 1,
-		0, 2,
-		0, 4,
-		1, 5,
+		gocube.CornerULF, gocube.CornerUFR,
+		gocube.CornerDRF, gocube.CornerUFR,
+		gocube.CornerDFL, gocube.CornerDRF,
+		gocube.CornerDFL, gocube.CornerULF,
+		gocube.CornerUBL, gocube.CornerULF,
+		gocube.CornerURB, gocube.CornerUFR,
+		gocube.CornerUBL, gocube.CornerURB,
+		gocube.CornerDBR, gocube.CornerURB,
+		gocube.CornerDLB, gocube.CornerDBR,
+		gocube.CornerDLB, gocube.CornerUBL,
+		gocube.CornerDLB, gocube.CornerDFL,
+		gocube.CornerDBR, gocube.CornerDRF,
 	}
 
 	edgesForBlocks := []int{
-		1, 3, 4, 5,
-		0, 2, 5, 11,
-		1, 3, 10, 11,
-		0, 2, 4, 10,
-		0, 6, 3, 9,
-		0, 6, 1, 7,
-		4, 5, 7, 9,
-		5, 11, 6, 8,
-		7, 9, 10, 11,
-		4, 10, 6, 8,
-		2, 8, 3, 9,
-		1, 7, 2, 8,
+		gocube.EdgeFR, gocube.EdgeFL, gocube.EdgeUL, gocube.EdgeUR,
+		gocube.EdgeUF, gocube.EdgeDF, gocube.EdgeUR, gocube.EdgeDR,
+		gocube.EdgeFR, gocube.EdgeFL, gocube.EdgeDL, gocube.EdgeDR,
+		gocube.EdgeUF, gocube.EdgeDF, gocube.EdgeUL, gocube.EdgeDL,
+		gocube.EdgeUF, gocube.EdgeUB, gocube.EdgeFL, gocube.EdgeBL,
+		gocube.EdgeUF, gocube.EdgeUB, gocube.EdgeFR, gocube.EdgeBR,
+		gocube.EdgeUL, gocube.EdgeUR, gocube.EdgeBR, gocube.EdgeBL,
+		gocube.EdgeUR, gocube.EdgeDR, gocube.EdgeUB, gocube.EdgeDB,
+		gocube.EdgeBR, gocube.EdgeBL, gocube.EdgeDL, gocube.EdgeDR,
+		gocube.EdgeUL, gocube.EdgeDL, gocube.EdgeUB, gocube.EdgeDB,
+		gocube.EdgeDF, gocube.EdgeDB, gocube.EdgeFL, gocube.EdgeBL,
+		gocube.EdgeFR, gocube.EdgeBR, gocube.EdgeDF, gocube.EdgeDB,
 	}
 
 OuterLoop:
@@ -72,52 +76,20 @@ OuterLoop:
 // TwoStep2x2x3 finds solutions to 2x2x3 blocks by first solving 2x2x2 blocks
 // and going from there.
 func TwoStep2x2x3(cube gocube.CubieCube) <-chan []gocube.Move {
-	channel := make(chan []gocube.Move, 1)
-	go func() {
-		for smallBlock := range Solve2x2x2(cube) {
-			start := cube
-			for _, move := range smallBlock {
-				start.Move(move)
-			}
-			lastFace := -1
-			if len(smallBlock) > 0 {
-				lastFace = smallBlock[len(smallBlock)-1].Face()
-			}
-			moves := iterative2x2x3(start, lastFace)
-			channel <- append(smallBlock, moves...)
-		}
-	}()
-	return channel
+	return TwoStep2x2x3Context(context.Background(), cube, nil)
 }
 
-func iterative2x2x3(start gocube.CubieCube, lastFace int) []gocube.Move {
-	for depth := 0; true; depth++ {
-		if solution := solve2x2x3(start, depth, lastFace); solution != nil {
-			return solution
-		}
-	}
-	return nil
-}
-
-func solve2x2x3(start gocube.CubieCube, depth, lastFace int) []gocube.Move {
-	if depth == 0 {
-		if solved, _ := Is2x2x3Solved(start); solved {
-			return []gocube.Move{}
-		} else {
-			return nil
-		}
-	}
-	for m := 0; m < 18; m++ {
-		move := gocube.Move(m)
-		face := move.Face()
-		if face == lastFace {
-			continue
-		}
-		newCube := start
-		newCube.Move(move)
-		if solution := solve2x2x3(newCube, depth-1, face); solution != nil {
-			return append([]gocube.Move{move}, solution...)
-		}
-	}
-	return nil
+// TwoStep2x2x3Context is like TwoStep2x2x3, but it stops when ctx is done, and
+// it reports its progress to the Phase2x2x2 and Phase2x2x3 phases of an
+// observer, which may be nil.
+func TwoStep2x2x3Context(ctx context.Context, cube gocube.CubieCube,
+	observer *gocube.SearchObserver) <-chan []gocube.Move {
+	return extendSolutions(ctx, cube, Solve2x2x2Context(ctx, cube, observer),
+		SearchOptions{
+			Goal: func(c gocube.CubieCube) bool {
+				solved, _ := Is2x2x3Solved(c)
+				return solved
+			},
+			Observer: observer.Phase(Phase2x2x3),
+		})
 }
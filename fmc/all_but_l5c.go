@@ -1,10 +1,14 @@
 package fmc
 
-import "github.com/unixpickle/gocube"
+import (
+	"context"
+	"sync"
 
-var globalEdgesHeuristic *EdgesHeuristic
+	"github.com/unixpickle/gocube"
+)
 
-const globalEdgesHeuristicDepth = 5
+var globalEdgesHeuristicOnce sync.Once
+var globalEdgesHeuristic func(c gocube.CubieCube) int
 
 // IsAllButL5CSolved returns true if the cube is almost solved (having up to 5
 // unsolved corners).
@@ -26,60 +30,26 @@ func IsAllButL5CSolved(cube gocube.CubieCube) bool {
 
 // FourStepAllButL5C finds solutions to everything but the last 5 corners by
 // solving the F2L-1 and going from there.
+//
+// The first call generates the pattern databases from
+// NewEdgesPatternDatabases, two tables with 42.5 million entries each, which
+// takes a while and keeps about 40MB of memory for the rest of the process.
 func FourStepAllButL5C(cube gocube.CubieCube) <-chan []gocube.Move {
-	if globalEdgesHeuristic == nil {
-		heuristic := NewEdgesHeuristic(globalEdgesHeuristicDepth)
-		globalEdgesHeuristic = &heuristic
-	}
-
-	channel := make(chan []gocube.Move, 1)
-	go func() {
-		for f2l1Solution := range ThreeStepF2LMinus1(cube) {
-			start := cube
-			for _, move := range f2l1Solution {
-				start.Move(move)
-			}
-			lastFace := -1
-			if len(f2l1Solution) > 0 {
-				lastFace = f2l1Solution[len(f2l1Solution)-1].Face()
-			}
-			moves := iterativeAllButL5C(start, lastFace)
-			channel <- append(f2l1Solution, moves...)
-		}
-	}()
-	return channel
-}
-
-func iterativeAllButL5C(start gocube.CubieCube, lastFace int) []gocube.Move {
-	for depth := 0; true; depth++ {
-		if solution := solveAllButL5C(start, depth, lastFace); solution != nil {
-			return solution
-		}
-	}
-	return nil
+	return FourStepAllButL5CContext(context.Background(), cube, nil)
 }
 
-func solveAllButL5C(start gocube.CubieCube, depth, lastFace int) []gocube.Move {
-	if depth == 0 {
-		if solved := IsAllButL5CSolved(start); solved {
-			return []gocube.Move{}
-		} else {
-			return nil
-		}
-	} else if globalEdgesHeuristic.Lookup(start.Edges) > depth {
-		return nil
-	}
-	for m := 0; m < 18; m++ {
-		move := gocube.Move(m)
-		face := move.Face()
-		if face == lastFace {
-			continue
-		}
-		newCube := start
-		newCube.Move(move)
-		if solution := solveAllButL5C(newCube, depth-1, face); solution != nil {
-			return append([]gocube.Move{move}, solution...)
-		}
-	}
-	return nil
+// FourStepAllButL5CContext is like FourStepAllButL5C, but it stops when ctx is
+// done, and it reports its progress to the Phase2x2x2, Phase2x2x3,
+// PhaseF2LMinus1 and PhaseAllButL5C phases of an observer, which may be nil.
+func FourStepAllButL5CContext(ctx context.Context, cube gocube.CubieCube,
+	observer *gocube.SearchObserver) <-chan []gocube.Move {
+	globalEdgesHeuristicOnce.Do(func() {
+		globalEdgesHeuristic = PatternHeuristic(NewEdgesPatternDatabases()...)
+	})
+	f2l1 := ThreeStepF2LMinus1Context(ctx, cube, observer)
+	return extendSolutions(ctx, cube, f2l1, SearchOptions{
+		Goal:      IsAllButL5CSolved,
+		Heuristic: globalEdgesHeuristic,
+		Observer:  observer.Phase(PhaseAllButL5C),
+	})
 }
@@ -1,35 +1,39 @@
 package fmc
 
-import "github.com/unixpickle/gocube"
+import (
+	"context"
+
+	"github.com/unixpickle/gocube"
+)
 
 // IsF2LMinus1Solved returns true if any F2L-1 is solved. It returns the face of
 // the F2L-1 cross and the corner index of the pair which is not solved.
 func IsF2LMinus1Solved(state gocube.CubieCube) (solved bool, face, corner int) {
 	crossEdges := []int{
-		0, 4, 5, 6,
-		2, 8, 10, 11,
-		0, 1, 2, 3,
-		6, 7, 8, 9,
-		1, 5, 7, 11,
-		3, 4, 9, 10,
+		gocube.EdgeUF, gocube.EdgeUL, gocube.EdgeUR, gocube.EdgeUB,
+		gocube.EdgeDF, gocube.EdgeDB, gocube.EdgeDL, gocube.EdgeDR,
+		gocube.EdgeUF, gocube.EdgeFR, gocube.EdgeDF, gocube.EdgeFL,
+		gocube.EdgeUB, gocube.EdgeBR, gocube.EdgeDB, gocube.EdgeBL,
+		gocube.EdgeFR, gocube.EdgeUR, gocube.EdgeBR, gocube.EdgeDR,
+		gocube.EdgeFL, gocube.EdgeUL, gocube.EdgeBL, gocube.EdgeDL,
 	}
 
 	pairEdges := []int{
-		1, 3, 7, 9,
-		1, 3, 7, 9,
-		4, 5, 10, 11,
-		4, 5, 10, 11,
-		0, 2, 6, 8,
-		0, 2, 6, 8,
+		gocube.EdgeFR, gocube.EdgeFL, gocube.EdgeBR, gocube.EdgeBL,
+		gocube.EdgeFR, gocube.EdgeFL, gocube.EdgeBR, gocube.EdgeBL,
+		gocube.EdgeUL, gocube.EdgeUR, gocube.EdgeDL, gocube.EdgeDR,
+		gocube.EdgeUL, gocube.EdgeUR, gocube.EdgeDL, gocube.EdgeDR,
+		gocube.EdgeUF, gocube.EdgeDF, gocube.EdgeUB, gocube.EdgeDB,
+		gocube.EdgeUF, gocube.EdgeDF, gocube.EdgeUB, gocube.EdgeDB,
 	}
 
 	pairCorners := []int{
-		7, 6, 3, 2,
-		5, 4, 1, 0,
-		6, 7, 4, 5,
-		2, 3, 0, 1,
-		7, 5, 3, 1,
-		6, 4, 2, 0,
+		gocube.CornerUFR, gocube.CornerULF, gocube.CornerURB, gocube.CornerUBL,
+		gocube.CornerDRF, gocube.CornerDFL, gocube.CornerDBR, gocube.CornerDLB,
+		gocube.CornerULF, gocube.CornerUFR, gocube.CornerDFL, gocube.CornerDRF,
+		gocube.CornerUBL, gocube.CornerURB, gocube.CornerDLB, gocube.CornerDBR,
+		gocube.CornerUFR, gocube.CornerDRF, gocube.CornerURB, gocube.CornerDBR,
+		gocube.CornerULF, gocube.CornerDFL, gocube.CornerUBL, gocube.CornerDLB,
 	}
 
 	var edgesSolved [12]bool
@@ -79,52 +83,20 @@ CrossLoop:
 // ThreeStepF2LMinus1 finds solutions to the F2L-1 by solving 2x2x3 in two steps
 // and then expanding them to F2L-1.
 func ThreeStepF2LMinus1(cube gocube.CubieCube) <-chan []gocube.Move {
-	channel := make(chan []gocube.Move, 1)
-	go func() {
-		for blockSolution := range TwoStep2x2x3(cube) {
-			start := cube
-			for _, move := range blockSolution {
-				start.Move(move)
-			}
-			lastFace := -1
-			if len(blockSolution) > 0 {
-				lastFace = blockSolution[len(blockSolution)-1].Face()
-			}
-			moves := iterativef2lminus1(start, lastFace)
-			channel <- append(blockSolution, moves...)
-		}
-	}()
-	return channel
+	return ThreeStepF2LMinus1Context(context.Background(), cube, nil)
 }
 
-func iterativef2lminus1(start gocube.CubieCube, lastFace int) []gocube.Move {
-	for depth := 0; true; depth++ {
-		if solution := solvef2lminus1(start, depth, lastFace); solution != nil {
-			return solution
-		}
-	}
-	return nil
-}
-
-func solvef2lminus1(start gocube.CubieCube, depth, lastFace int) []gocube.Move {
-	if depth == 0 {
-		if solved, _, _ := IsF2LMinus1Solved(start); solved {
-			return []gocube.Move{}
-		} else {
-			return nil
-		}
-	}
-	for m := 0; m < 18; m++ {
-		move := gocube.Move(m)
-		face := move.Face()
-		if face == lastFace {
-			continue
-		}
-		newCube := start
-		newCube.Move(move)
-		if solution := solvef2lminus1(newCube, depth-1, face); solution != nil {
-			return append([]gocube.Move{move}, solution...)
-		}
-	}
-	return nil
+// ThreeStepF2LMinus1Context is like ThreeStepF2LMinus1, but it stops when ctx
+// is done, and it reports its progress to the Phase2x2x2, Phase2x2x3 and
+// PhaseF2LMinus1 phases of an observer, which may be nil.
+func ThreeStepF2LMinus1Context(ctx context.Context, cube gocube.CubieCube,
+	observer *gocube.SearchObserver) <-chan []gocube.Move {
+	return extendSolutions(ctx, cube, TwoStep2x2x3Context(ctx, cube, observer),
+		SearchOptions{
+			Goal: func(c gocube.CubieCube) bool {
+				solved, _, _ := IsF2LMinus1Solved(c)
+				return solved
+			},
+			Observer: observer.Phase(PhaseF2LMinus1),
+		})
 }
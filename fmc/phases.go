@@ -0,0 +1,11 @@
+package fmc
+
+// These are the phases which the fmc searches report to a
+// gocube.SearchObserver. Each step of a multi-step search is its own phase,
+// so that its progress can be told apart from the steps before it.
+const (
+	Phase2x2x2 = iota
+	Phase2x2x3
+	PhaseF2LMinus1
+	PhaseAllButL5C
+)
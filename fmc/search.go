@@ -0,0 +1,218 @@
+package fmc
+
+import (
+	"context"
+	"time"
+
+	"github.com/unixpickle/gocube"
+)
+
+// SearchOptions describes a search for move sequences which reach a goal.
+type SearchOptions struct {
+	// Goal returns true for the states which the search is looking for.
+	Goal func(c gocube.CubieCube) bool
+
+	// Heuristic, if it is non-nil, returns a lower bound on the number of
+	// moves needed to reach the goal from a state. It must never overestimate
+	// this number, or solutions will be missed.
+	Heuristic func(c gocube.CubieCube) int
+
+	// LastFace is the face of the move which was made right before the
+	// search, or 0 if there was none. The first move of every solution is on
+	// a different face.
+	LastFace int
+
+	// Moves lists the moves to search with. If it is nil, all 18 moves are
+	// used.
+	Moves []gocube.Move
+
+	// Limit is the number of solutions after which the search stops. If it
+	// is 0, there is no limit.
+	Limit int
+
+	// MaxDepth is the length of the longest solution to search for. If it is
+	// 0, there is no maximum.
+	MaxDepth int
+
+	// Observer, if it is non-nil, collects statistics about the search.
+	Observer *gocube.PhaseObserver
+}
+
+// Search runs an iterative deepening search for solutions to a goal.
+//
+// Solutions are delivered shortest first. A solution never has two moves in a
+// row on the same face, and two moves on opposite faces are only made in one
+// order, so the search does not deliver equivalent solutions.
+//
+// The channel is closed once the search is finished, which happens when ctx is
+// done, when the limit is reached, or after the maximum depth. Without a limit
+// or a maximum depth, the search only stops when ctx is done.
+func Search(ctx context.Context, start gocube.CubieCube,
+	opts SearchOptions) <-chan []gocube.Move {
+	moves := opts.Moves
+	if moves == nil {
+		moves = make([]gocube.Move, 18)
+		for i := range moves {
+			moves[i] = gocube.Move(i)
+		}
+	}
+	s := &searcher{
+		ctx:       ctx,
+		goal:      opts.Goal,
+		heuristic: opts.Heuristic,
+		moves:     moves,
+		remaining: opts.Limit,
+		observer:  opts.Observer,
+		output:    make(chan []gocube.Move),
+	}
+	go s.run(start, opts.LastFace, opts.MaxDepth)
+	return s.output
+}
+
+type searcher struct {
+	ctx       context.Context
+	goal      func(c gocube.CubieCube) bool
+	heuristic func(c gocube.CubieCube) int
+	moves     []gocube.Move
+	observer  *gocube.PhaseObserver
+	output    chan []gocube.Move
+
+	// remaining is the number of solutions left to deliver, or 0 if there is
+	// no limit.
+	remaining int
+
+	// searchStart is the time when the search last resumed after delivering
+	// a solution.
+	searchStart time.Time
+}
+
+func (s *searcher) run(start gocube.CubieCube, lastFace, maxDepth int) {
+	defer close(s.output)
+	s.searchStart = s.observer.StartSearch()
+	defer func() {
+		s.observer.EndSearch(s.searchStart)
+	}()
+	for depth := 0; maxDepth == 0 || depth <= maxDepth; depth++ {
+		s.observer.StartDepth(depth)
+		moves := make([]gocube.Move, 0, depth)
+		if !s.depthFirst(&start, moves, depth, lastFace, true) {
+			return
+		}
+	}
+}
+
+// depthFirst searches for solutions of a given length. It returns false if
+// the search should stop.
+//
+// The seam argument is true for the first move of the search, since the move
+// before it was not chosen by the search and may not be in canonical order.
+func (s *searcher) depthFirst(c *gocube.CubieCube, moves []gocube.Move,
+	depth, lastFace int, seam bool) bool {
+	s.observer.Node()
+	if depth == 0 {
+		if s.goal(*c) {
+			res := make([]gocube.Move, len(moves))
+			copy(res, moves)
+			return s.deliver(res)
+		}
+		return true
+	} else if s.heuristic != nil && s.heuristic(*c) > depth {
+		s.observer.Prune()
+		return true
+	} else if depth >= 4 && s.ctx.Err() != nil {
+		return false
+	}
+
+	for _, move := range s.moves {
+		face := move.Face()
+		if face == lastFace {
+			continue
+		} else if !seam && isOppositeFace(face, lastFace) && face < lastFace {
+			// Moves on opposite faces commute, so only one order is tried.
+			continue
+		}
+		cube := *c
+		cube.Move(move)
+		if !s.depthFirst(&cube, append(moves, move), depth-1, face, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// deliver sends a solution. It returns false if the search should stop.
+func (s *searcher) deliver(solution []gocube.Move) bool {
+	// The time spent waiting for the solution to be received is not part of
+	// the search.
+	s.observer.EndSearch(s.searchStart)
+	defer func() {
+		s.searchStart = time.Now()
+	}()
+	select {
+	case <-s.ctx.Done():
+		return false
+	case s.output <- solution:
+	}
+	if s.remaining > 0 {
+		s.remaining--
+		return s.remaining > 0
+	}
+	return true
+}
+
+func isOppositeFace(f1, f2 int) bool {
+	return f1 != f2 && (f1-1)/2 == (f2-1)/2
+}
+
+// extendSolutions finds the shortest solution to the next step of a search
+// for each solution to the previous step, and delivers the combined solutions.
+// The opts configure the search for the next step, except for LastFace and
+// Limit.
+func extendSolutions(ctx context.Context, cube gocube.CubieCube,
+	previous <-chan []gocube.Move, opts SearchOptions) <-chan []gocube.Move {
+	channel := make(chan []gocube.Move, 1)
+	go func() {
+		defer close(channel)
+		for solution := range previous {
+			start := cube
+			for _, move := range solution {
+				start.Move(move)
+			}
+			opts.LastFace = lastFace(solution)
+			opts.Limit = 1
+			moves, ok := <-Search(ctx, start, opts)
+			if !ok {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case channel <- append(solution, moves...):
+			}
+		}
+	}()
+	return channel
+}
+
+// lastFace returns the face of the final move, or 0 if there are no moves.
+func lastFace(moves []gocube.Move) int {
+	if len(moves) == 0 {
+		return 0
+	}
+	return moves[len(moves)-1].Face()
+}
+
+// PatternHeuristic creates a heuristic for SearchOptions which returns the
+// largest lower bound given by a set of pattern databases.
+func PatternHeuristic(dbs ...*gocube.PatternDatabase) func(
+	c gocube.CubieCube) int {
+	return func(c gocube.CubieCube) int {
+		var res int
+		for _, db := range dbs {
+			if bound := db.Lookup(&c); bound > res {
+				res = bound
+			}
+		}
+		return res
+	}
+}
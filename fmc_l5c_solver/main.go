@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -16,12 +17,12 @@ func main() {
 		fmt.Println("Failed to read stickers:", err)
 		os.Exit(1)
 	}
-	cc, err := sc.CubieCube()
-	if err != nil {
+	if err := sc.Validate(); err != nil {
 		fmt.Println("Invalid stickers:", err)
 		os.Exit(1)
 	}
-	heuristic := fmc.NewEdgesHeuristic(5)
+	cc, _ := sc.CubieCube()
+	heuristic := fmc.PatternHeuristic(fmc.NewEdgesPatternDatabases()...)
 
 	if cc.Corners[1].Piece == 1 && cc.Corners[1].Orientation == 1 {
 		thirdF2LCorner = 1
@@ -29,33 +30,21 @@ func main() {
 		thirdF2LCorner = 5
 	}
 
-	for depth := 0; depth <= 20; depth++ {
-		fmt.Println("Searching depth", depth)
-		if solution := Search(*cc, heuristic, depth); solution != nil {
-			fmt.Println("Got a solution:", solution)
-			break
-		}
+	observer := &gocube.SearchObserver{
+		OnDepth: func(phase, depth int) {
+			fmt.Println("Searching depth", depth)
+		},
 	}
-}
-
-func Search(start gocube.CubieCube, heuristic fmc.EdgesHeuristic, d int) []gocube.Move {
-	if d == 0 {
-		if IsSolved(start) {
-			return []gocube.Move{}
-		} else {
-			return nil
-		}
-	} else if heuristic.Lookup(start.Edges) > d {
-		return nil
-	}
-	for m := 0; m < 18; m++ {
-		newCube := start
-		newCube.Move(gocube.Move(m))
-		if solution := Search(newCube, heuristic, d-1); solution != nil {
-			return append([]gocube.Move{gocube.Move(m)}, solution...)
-		}
+	solutions := fmc.Search(context.Background(), *cc, fmc.SearchOptions{
+		Goal:      IsSolved,
+		Heuristic: heuristic,
+		Limit:     1,
+		MaxDepth:  20,
+		Observer:  observer.Phase(0),
+	})
+	for solution := range solutions {
+		fmt.Println("Got a solution:", gocube.NewAlgorithm(solution))
 	}
-	return nil
 }
 
 func IsSolved(state gocube.CubieCube) bool {
@@ -0,0 +1,246 @@
+package gocube
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// ImageOptions controls how a cube is rendered as an isometric image.
+type ImageOptions struct {
+	// Colors contains the color of each sticker color, indexed by sticker
+	// value (so the first entry is unused).
+	Colors [7]color.Color
+
+	// Size is the height of the image in pixels. The width is determined by
+	// the height.
+	Size int
+
+	// Gap is the width of the border around each sticker, as a fraction of
+	// the width of a sticker.
+	Gap float64
+
+	// BorderColor is drawn behind the stickers of each face, so it shows
+	// through the gaps between them.
+	BorderColor color.Color
+
+	// Background is the color of the whole image. If it is nil, the image is
+	// transparent.
+	Background color.Color
+
+	// Mask indicates which stickers (indexed like a StickerCube) should be
+	// drawn in MaskColor instead of their own colors.
+	Mask [54]bool
+
+	// MaskColor is used for masked stickers and for stickers with invalid
+	// values.
+	MaskColor color.Color
+
+	// BackFaces adds a second view of the cube, as seen from the opposite
+	// corner, to the right of the first one. The second view shows the D face
+	// on top, the L face on the left and the B face on the right.
+	BackFaces bool
+}
+
+// DefaultImageOptions returns the options used when no options are given. The
+// colors match DefaultSVGOptions().
+func DefaultImageOptions() *ImageOptions {
+	return &ImageOptions{
+		Colors: [7]color.Color{nil, color.RGBA{0xff, 0xff, 0xff, 0xff},
+			color.RGBA{0xff, 0xff, 0, 0xff}, color.RGBA{0, 0xff, 0, 0xff},
+			color.RGBA{0, 0, 0xff, 0xff}, color.RGBA{0xff, 0, 0, 0xff},
+			color.RGBA{0xff, 0x80, 0, 0xff}},
+		Size:        120,
+		Gap:         0.1,
+		BorderColor: color.Black,
+		MaskColor:   color.RGBA{0x80, 0x80, 0x80, 0xff},
+	}
+}
+
+// isometricFaces describes the position of each face's stickers. For each face,
+// it gives the corner of the first sticker, the direction along a row, and the
+// direction to the next row, all in a coordinate system where the cube spans
+// [0, 3] along the x, y and z axes described in CubieCorner.
+var isometricFaces = [6][3][3]float64{
+	{{0, 3, 0}, {1, 0, 0}, {0, 0, 1}},
+	{{0, 0, 3}, {1, 0, 0}, {0, 0, -1}},
+	{{0, 3, 3}, {1, 0, 0}, {0, -1, 0}},
+	{{3, 3, 0}, {-1, 0, 0}, {0, -1, 0}},
+	{{3, 3, 3}, {0, 0, -1}, {0, -1, 0}},
+	{{0, 3, 0}, {0, 0, 1}, {0, -1, 0}},
+}
+
+// Visible faces in the front and back views, respectively.
+var (
+	isometricFrontFaces = []int{1, 3, 5}
+	isometricBackFaces  = []int{2, 4, 6}
+)
+
+// IsometricImage renders the stickers as they would appear when looking at the
+// UFR corner of the cube, so that the U, F and R faces are visible.
+//
+// If opts is nil, DefaultImageOptions() is used.
+func (s *StickerCube) IsometricImage(opts *ImageOptions) *image.RGBA {
+	if opts == nil {
+		opts = DefaultImageOptions()
+	}
+
+	// The projection of the cube is a hexagon which is 6 units tall and
+	// 3*sqrt(3) units wide, and we leave a small margin around it.
+	scale := float64(opts.Size) / 6.5
+	viewWidth := int(math.Ceil(scale * (3*math.Sqrt(3) + 0.5)))
+	width := viewWidth
+	if opts.BackFaces {
+		width *= 2
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, opts.Size))
+	if opts.Background != nil {
+		fillPolygon(img, []float64{0, 0, float64(width), 0, float64(width),
+			float64(opts.Size), 0, float64(opts.Size)}, opts.Background)
+	}
+
+	s.drawIsometricView(img, isometricFrontFaces, false,
+		float64(viewWidth)/2, scale, opts)
+	if opts.BackFaces {
+		s.drawIsometricView(img, isometricBackFaces, true,
+			float64(viewWidth)*1.5, scale, opts)
+	}
+	return img
+}
+
+// WritePNG encodes the result of IsometricImage as a PNG.
+func (s *StickerCube) WritePNG(w io.Writer, opts *ImageOptions) error {
+	return png.Encode(w, s.IsometricImage(opts))
+}
+
+// IsometricImage renders the cube in 3D. See StickerCube.IsometricImage for
+// details.
+func (c *CubieCube) IsometricImage(opts *ImageOptions) *image.RGBA {
+	stickers := c.StickerCube()
+	return stickers.IsometricImage(opts)
+}
+
+// WritePNG encodes the result of IsometricImage as a PNG.
+func (c *CubieCube) WritePNG(w io.Writer, opts *ImageOptions) error {
+	return png.Encode(w, c.IsometricImage(opts))
+}
+
+func (s *StickerCube) drawIsometricView(img *image.RGBA, faces []int,
+	back bool, centerX, scale float64, opts *ImageOptions) {
+	centerY := float64(img.Bounds().Dy()) / 2
+	project := func(p [3]float64) (float64, float64) {
+		x, y, z := p[0]-1.5, p[1]-1.5, p[2]-1.5
+		if back {
+			// A half turn around the axis through the middle of the UL and DR
+			// edges brings the DBL corner to the front.
+			x, y, z = -z, -y, -x
+		}
+		return centerX + scale*(x-z)*math.Sqrt(3)/2,
+			centerY + scale*((x+z)/2-y)
+	}
+	quad := func(corner, col, row [3]float64, colSize, rowSize float64) []float64 {
+		var res []float64
+		for _, offset := range [][2]float64{{0, 0}, {colSize, 0},
+			{colSize, rowSize}, {0, rowSize}} {
+			var p [3]float64
+			for i := range p {
+				p[i] = corner[i] + col[i]*offset[0] + row[i]*offset[1]
+			}
+			x, y := project(p)
+			res = append(res, x, y)
+		}
+		return res
+	}
+
+	for _, face := range faces {
+		info := isometricFaces[face-1]
+		corner, col, row := info[0], info[1], info[2]
+		fillPolygon(img, quad(corner, col, row, 3, 3), opts.BorderColor)
+		for i := 0; i < 9; i++ {
+			var start [3]float64
+			for j := range start {
+				start[j] = corner[j] + col[j]*(float64(i%3)+opts.Gap/2) +
+					row[j]*(float64(i/3)+opts.Gap/2)
+			}
+			idx := (face-1)*9 + i
+			fillPolygon(img, quad(start, col, row, 1-opts.Gap, 1-opts.Gap),
+				opts.stickerColor(s[idx], opts.Mask[idx]))
+		}
+	}
+}
+
+func (o *ImageOptions) stickerColor(sticker int, masked bool) color.Color {
+	if masked || sticker < 1 || sticker > 6 {
+		return o.MaskColor
+	}
+	return o.Colors[sticker]
+}
+
+// fillPolygon fills a convex polygon, given as a list of x and y coordinates.
+//
+// The edges of the polygon are antialiased by sampling each pixel on a 4x4
+// grid.
+func fillPolygon(img *image.RGBA, points []float64, c color.Color) {
+	if c == nil {
+		return
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i < len(points); i += 2 {
+		minX, maxX = math.Min(minX, points[i]), math.Max(maxX, points[i])
+		minY, maxY = math.Min(minY, points[i+1]), math.Max(maxY, points[i+1])
+	}
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)),
+		int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(img.Bounds())
+
+	r, g, b, a := c.RGBA()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var coverage uint32
+			for sy := 0; sy < 4; sy++ {
+				for sx := 0; sx < 4; sx++ {
+					if insideConvexPolygon(points, float64(x)+(float64(sx)+0.5)/4,
+						float64(y)+(float64(sy)+0.5)/4) {
+						coverage++
+					}
+				}
+			}
+			if coverage == 0 {
+				continue
+			}
+			// Blend the premultiplied color over the existing pixel.
+			dst := img.RGBAAt(x, y)
+			srcA := a * coverage / 16
+			blend := func(d uint8, s uint32) uint8 {
+				return uint8((uint32(d)*0x101*(0xffff-srcA)/0xffff +
+					s*coverage/16) >> 8)
+			}
+			img.SetRGBA(x, y, color.RGBA{
+				R: blend(dst.R, r),
+				G: blend(dst.G, g),
+				B: blend(dst.B, b),
+				A: blend(dst.A, a),
+			})
+		}
+	}
+}
+
+func insideConvexPolygon(points []float64, x, y float64) bool {
+	var sign float64
+	for i := 0; i < len(points); i += 2 {
+		j := (i + 2) % len(points)
+		cross := (points[j]-points[i])*(y-points[i+1]) -
+			(points[j+1]-points[i+1])*(x-points[i])
+		if cross == 0 {
+			continue
+		} else if sign == 0 {
+			sign = cross
+		} else if (cross > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package gocube
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestIsometricImage(t *testing.T) {
+	opts := DefaultImageOptions()
+	opts.Size = 130
+	opts.BackFaces = true
+
+	cube := SolvedStickerCube()
+	cube[4] = 3
+	img := cube.IsometricImage(opts)
+	if img.Bounds().Dx() != 228 || img.Bounds().Dy() != 130 {
+		t.Fatalf("unexpected bounds %v", img.Bounds())
+	}
+
+	// Check the center sticker of each face, which is where the projection of
+	// the middle of the face lands.
+	points := []struct {
+		x, y  int
+		color int
+	}{
+		{57, 35, 3},
+		{31, 80, 3},
+		{83, 80, 5},
+		{171, 35, 2},
+		{145, 80, 6},
+		{197, 80, 4},
+	}
+	for _, p := range points {
+		expected := color.RGBAModel.Convert(opts.Colors[p.color])
+		if actual := img.RGBAAt(p.x, p.y); actual != expected {
+			t.Errorf("pixel (%d, %d) should be %v but got %v", p.x, p.y,
+				expected, actual)
+		}
+	}
+
+	if img.RGBAAt(0, 0).A != 0 {
+		t.Error("background should be transparent")
+	}
+	opts.Background = color.White
+	if img := cube.IsometricImage(opts); img.RGBAAt(0, 0) !=
+		(color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Error("background should be white")
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	cube := SolvedCubieCube()
+	var buf bytes.Buffer
+	if err := cube.WritePNG(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dy() != DefaultImageOptions().Size {
+		t.Errorf("unexpected bounds %v", img.Bounds())
+	}
+}
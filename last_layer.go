@@ -0,0 +1,199 @@
+package gocube
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// A LastLayerMode determines what a last-layer diagram shows.
+type LastLayerMode int
+
+const (
+	// LastLayerFull shows every sticker in its own color.
+	LastLayerFull LastLayerMode = iota
+
+	// LastLayerOLL shows only the stickers with the color of the U center, as
+	// in OLL diagrams. Other stickers are drawn in the mask color.
+	LastLayerOLL
+
+	// LastLayerPLL shows every sticker, along with arrows showing where the
+	// pieces of the U layer must move to be solved.
+	LastLayerPLL
+)
+
+// lastLayerSides lists the sticker indexes of the side stickers around the U
+// face, in the order they are drawn: B and F from left to right, then L and R
+// from top to bottom.
+var lastLayerSides = [4][3]int{
+	{29, 28, 27},
+	{18, 19, 20},
+	{45, 46, 47},
+	{38, 37, 36},
+}
+
+// LastLayerSVG renders a top view of the U layer, showing the U face and the
+// top row of stickers of each side face.
+//
+// The F face is at the bottom of the diagram. If opts is nil,
+// DefaultSVGOptions() is used; the FaceGap is used as the margin around the
+// diagram.
+func (c *CubieCube) LastLayerSVG(mode LastLayerMode, opts *SVGOptions) string {
+	if opts == nil {
+		opts = DefaultSVGOptions()
+	}
+	stickers := c.StickerCube()
+
+	size := opts.StickerSize
+	strip := size * 0.4
+	faceSize := 3*size + 4*opts.Gap
+	faceX := opts.FaceGap + strip + opts.Gap
+	total := 2*faceX + faceSize
+
+	var res strings.Builder
+	fmt.Fprintf(&res, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" `+
+		`height="%s" viewBox="0 0 %s %s">`+"\n", svgNumber(total),
+		svgNumber(total), svgNumber(total), svgNumber(total))
+	if mode == LastLayerPLL {
+		fmt.Fprintf(&res, `<defs><marker id="arrow" markerWidth="6" `+
+			`markerHeight="6" refX="5" refY="3" orient="auto-start-reverse">`+
+			`<path d="M0,0 L6,3 L0,6 z" fill="%s"/></marker></defs>`+"\n",
+			svgEscape(opts.ArrowColor))
+	}
+	if opts.Background != "" {
+		writeSVGRect(&res, 0, 0, total, total, opts.Background)
+	}
+
+	color := func(idx int) string {
+		masked := opts.Mask[idx]
+		if mode == LastLayerOLL && stickers[idx] != stickers[4] {
+			masked = true
+		}
+		return opts.stickerColor(stickers[idx], masked)
+	}
+
+	writeSVGRect(&res, faceX, faceX, faceSize, faceSize, opts.BorderColor)
+	for i := 0; i < 9; i++ {
+		x, y := lastLayerStickerCenter(i, faceX, opts)
+		writeSVGRect(&res, x-size/2, y-size/2, size, size, color(i))
+	}
+
+	for side, indexes := range lastLayerSides {
+		for i, idx := range indexes {
+			offset := faceX + opts.Gap + float64(i)*(size+opts.Gap)
+			var x, y, width, height float64
+			switch side {
+			case 0:
+				x, y, width, height = offset, opts.FaceGap, size, strip
+			case 1:
+				x, y, width, height = offset, faceX+faceSize+opts.Gap, size,
+					strip
+			case 2:
+				x, y, width, height = opts.FaceGap, offset, strip, size
+			case 3:
+				x, y, width, height = faceX+faceSize+opts.Gap, offset, strip,
+					size
+			}
+			writeSVGRect(&res, x, y, width, height, color(idx))
+		}
+	}
+
+	if mode == LastLayerPLL {
+		for _, cycle := range c.lastLayerCycles() {
+			writeLastLayerArrows(&res, cycle, faceX, opts)
+		}
+	}
+
+	res.WriteString("</svg>\n")
+	return res.String()
+}
+
+// AlgorithmLastLayerSVG renders the last layer of the state which results from
+// applying an algorithm to a solved cube.
+//
+// To draw the case which an algorithm solves, pass the algorithm's inverse.
+func AlgorithmLastLayerSVG(a Algorithm, mode LastLayerMode,
+	opts *SVGOptions) string {
+	cube := SolvedOrientedCube()
+	cube.Apply(a)
+	return cube.Cube.LastLayerSVG(mode, opts)
+}
+
+// lastLayerCycles finds the cycles of pieces which must move within the U
+// layer to solve it. Each cycle is given as a list of U-face sticker indexes,
+// where the piece at each sticker must move to the next one.
+//
+// Cycles which involve pieces outside of the U layer are ignored.
+func (c *CubieCube) lastLayerCycles() [][]int {
+	var res [][]int
+
+	var cornerVisited [8]bool
+	for _, start := range []int{CornerUBL, CornerURB, CornerUFR, CornerULF} {
+		var cycle []int
+		inLayer := true
+		for slot := start; !cornerVisited[slot]; slot = c.Corners[slot].Piece {
+			cornerVisited[slot] = true
+			if CornerIndexes[slot*3+1] > 8 {
+				inLayer = false
+			}
+			cycle = append(cycle, CornerIndexes[slot*3+1])
+		}
+		if len(cycle) > 1 && inLayer {
+			res = append(res, cycle)
+		}
+	}
+
+	var edgeVisited [12]bool
+	for _, start := range []int{EdgeUB, EdgeUR, EdgeUF, EdgeUL} {
+		var cycle []int
+		inLayer := true
+		for slot := start; !edgeVisited[slot]; slot = c.Edges[slot].Piece {
+			edgeVisited[slot] = true
+			if EdgeIndexes[slot*2] > 8 {
+				inLayer = false
+			}
+			cycle = append(cycle, EdgeIndexes[slot*2])
+		}
+		if len(cycle) > 1 && inLayer {
+			res = append(res, cycle)
+		}
+	}
+
+	return res
+}
+
+func lastLayerStickerCenter(idx int, faceX float64, opts *SVGOptions) (x,
+	y float64) {
+	step := opts.StickerSize + opts.Gap
+	start := faceX + opts.Gap + opts.StickerSize/2
+	return start + float64(idx%3)*step, start + float64(idx/3)*step
+}
+
+func writeLastLayerArrows(w *strings.Builder, cycle []int, faceX float64,
+	opts *SVGOptions) {
+	count := len(cycle)
+	if count == 2 {
+		// A swap is drawn as a single arrow with two heads.
+		count = 1
+	}
+	for i := 0; i < count; i++ {
+		x1, y1 := lastLayerStickerCenter(cycle[i], faceX, opts)
+		x2, y2 := lastLayerStickerCenter(cycle[(i+1)%len(cycle)], faceX, opts)
+
+		// Keep the ends of the arrow away from the centers of the stickers.
+		dx, dy := x2-x1, y2-y1
+		length := math.Sqrt(dx*dx + dy*dy)
+		inset := opts.StickerSize / 4
+		x1, y1 = x1+dx*inset/length, y1+dy*inset/length
+		x2, y2 = x2-dx*inset/length, y2-dy*inset/length
+
+		markers := `marker-end="url(#arrow)"`
+		if len(cycle) == 2 {
+			markers = `marker-start="url(#arrow)" ` + markers
+		}
+		fmt.Fprintf(w, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" `+
+			`stroke-width="%s" %s/>`+"\n", svgNumber(x1), svgNumber(y1),
+			svgNumber(x2), svgNumber(y2), svgEscape(opts.ArrowColor),
+			svgNumber(opts.StickerSize/10), markers)
+	}
+}
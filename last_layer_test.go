@@ -0,0 +1,110 @@
+package gocube
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+type lastLayerImage struct {
+	Rects []svgRect `xml:"rect"`
+	Lines []struct {
+		MarkerStart string `xml:"marker-start,attr"`
+	} `xml:"line"`
+}
+
+func parseLastLayerSVG(t *testing.T, algorithm string, mode LastLayerMode,
+	opts *SVGOptions) *lastLayerImage {
+	a, err := ParseAlgorithm(algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var image lastLayerImage
+	svg := AlgorithmLastLayerSVG(a, mode, opts)
+	if err := xml.Unmarshal([]byte(svg), &image); err != nil {
+		t.Fatal(err)
+	}
+	return &image
+}
+
+func TestLastLayerSVGArrows(t *testing.T) {
+	// The T permutation swaps two corners and two edges.
+	image := parseLastLayerSVG(t, "R U R' U' R' F R2 U' R' U' R U R' F'",
+		LastLayerPLL, nil)
+	if len(image.Rects) != 1+9+12 {
+		t.Errorf("unexpected number of rectangles: %d", len(image.Rects))
+	}
+	if len(image.Lines) != 2 {
+		t.Fatalf("expected 2 arrows but got %d", len(image.Lines))
+	}
+	for _, line := range image.Lines {
+		if line.MarkerStart == "" {
+			t.Error("swap arrow should have two heads")
+		}
+	}
+
+	// The U permutation cycles three edges.
+	image = parseLastLayerSVG(t, "R U' R U R U R U' R' U' R2", LastLayerPLL,
+		nil)
+	if len(image.Lines) != 3 {
+		t.Fatalf("expected 3 arrows but got %d", len(image.Lines))
+	}
+	for _, line := range image.Lines {
+		if line.MarkerStart != "" {
+			t.Error("cycle arrow should have one head")
+		}
+	}
+
+	// Pieces which leave the U layer are not drawn.
+	image = parseLastLayerSVG(t, "R", LastLayerPLL, nil)
+	if len(image.Lines) != 0 {
+		t.Errorf("expected no arrows but got %d", len(image.Lines))
+	}
+}
+
+func TestLastLayerSVGOLL(t *testing.T) {
+	opts := DefaultSVGOptions()
+	image := parseLastLayerSVG(t, "R U R' U R U2 R'", LastLayerOLL, opts)
+
+	// The Sune leaves three corners twisted, so only one corner and the cross
+	// are white on top.
+	var white, masked int
+	for _, rect := range image.Rects[1:10] {
+		switch rect.Fill {
+		case opts.Colors[1]:
+			white++
+		case opts.MaskColor:
+			masked++
+		default:
+			t.Errorf("unexpected U sticker color %s", rect.Fill)
+		}
+	}
+	if white != 6 || masked != 3 {
+		t.Errorf("expected 6 white and 3 masked stickers, got %d and %d", white,
+			masked)
+	}
+
+	white = 0
+	for _, rect := range image.Rects[10:] {
+		if rect.Fill == opts.Colors[1] {
+			white++
+		} else if rect.Fill != opts.MaskColor {
+			t.Errorf("unexpected side sticker color %s", rect.Fill)
+		}
+	}
+	if white != 3 {
+		t.Errorf("expected 3 white side stickers, got %d", white)
+	}
+}
+
+func TestAlgorithmLastLayerSVG(t *testing.T) {
+	a, _ := ParseAlgorithm("R U R' U' R' F R2 U' R' U' R U R' F'")
+	cube := SolvedCubieCube()
+	orientation := StandardOrientation()
+	a.Apply(&cube, &orientation)
+	for _, mode := range []LastLayerMode{LastLayerFull, LastLayerOLL,
+		LastLayerPLL} {
+		if AlgorithmLastLayerSVG(a, mode, nil) != cube.LastLayerSVG(mode, nil) {
+			t.Errorf("mode %d does not match CubieCube rendering", mode)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package gocube
+
+// A Metric determines how the length of a solution is measured.
+type Metric int
+
+const (
+	// HTM is the half turn metric, where every face turn counts as one move.
+	HTM Metric = iota
+
+	// QTM is the quarter turn metric, where half turns count as two moves.
+	QTM
+
+	// STM is the slice turn metric, where slice turns count as one move. A
+	// slice turn is made of two turns of opposite faces in the same direction,
+	// like R L' (an M' slice plus an x rotation).
+	STM
+
+	metricCount
+)
+
+// String returns the abbreviation of the metric.
+func (m Metric) String() string {
+	switch m {
+	case HTM:
+		return "HTM"
+	case QTM:
+		return "QTM"
+	case STM:
+		return "STM"
+	default:
+		return "unknown metric"
+	}
+}
+
+// Length returns the length of a sequence of moves in the metric.
+//
+// In STM, consecutive turns of opposite faces count as one move if they make
+// up a slice turn.
+func (m Metric) Length(moves []Move) int {
+	var res int
+	for i := 0; i < len(moves); i++ {
+		switch m {
+		case QTM:
+			if moves[i].Turns() == 2 {
+				res += 2
+			} else {
+				res++
+			}
+		case STM:
+			if i+1 < len(moves) && isSlicePair(moves[i], moves[i+1]) {
+				i++
+			}
+			res++
+		default:
+			res++
+		}
+	}
+	return res
+}
+
+// isSlicePair checks if two moves turn opposite faces in the same direction,
+// making up a slice turn.
+func isSlicePair(m1, m2 Move) bool {
+	return m1.Face() == oppositeFace(m2.Face()) &&
+		(m1.Turns()+m2.Turns())%4 == 0
+}
+
+// A moveSet lists the moves which a search in some metric may apply at each
+// step, along with their costs.
+//
+// A search move is usually a single move, but in STM, it may also be a pair of
+// moves which make up a slice turn. Moves are given as indices into a list of
+// base moves, so the same code works for Move and Phase2Move.
+type moveSet struct {
+	base  []Move
+	moves []searchMove
+
+	// allowed indicates if a search move may follow another. The first index
+	// is one more than the index of the previous search move, or 0 at the
+	// start of a search.
+	allowed [][]bool
+
+	// scale is multiplied by the heuristic of a state to get a lower bound on
+	// the cost of solving it with these moves.
+	scale int
+}
+
+type searchMove struct {
+	moves []int
+	cost  int
+}
+
+// newMoveSet creates a moveSet from a list of base moves, each of which is
+// described by the equivalent Move.
+func newMoveSet(metric Metric, base []Move) *moveSet {
+	res := &moveSet{base: base, scale: 1}
+	for i, m := range base {
+		cost := 1
+		if metric == QTM && m.Turns() == 2 {
+			cost = 2
+		}
+		res.moves = append(res.moves, searchMove{moves: []int{i}, cost: cost})
+	}
+	if metric == STM {
+		for i, m1 := range base {
+			for j, m2 := range base {
+				if m1.Face() < m2.Face() && isSlicePair(m1, m2) {
+					res.moves = append(res.moves, searchMove{
+						moves: []int{i, j},
+						cost:  1,
+					})
+				}
+			}
+		}
+	}
+
+	// Turns of the same face are never consecutive, and turns of opposite
+	// faces commute, so they are only searched in one order. In STM, a slice
+	// turn is not combined with other turns on its axis, and two turns which
+	// make up a slice turn are only searched as a slice turn.
+	res.allowed = make([][]bool, len(res.moves)+1)
+	res.allowed[0] = make([]bool, len(res.moves))
+	for i := range res.moves {
+		res.allowed[0][i] = true
+	}
+	for i, prev := range res.moves {
+		res.allowed[i+1] = make([]bool, len(res.moves))
+		for j, next := range res.moves {
+			allowed := true
+			for _, m1 := range prev.moves {
+				for _, m2 := range next.moves {
+					if base[m1].Face() == base[m2].Face() {
+						allowed = false
+					}
+				}
+			}
+			lastMove := base[prev.moves[len(prev.moves)-1]]
+			nextMove := base[next.moves[0]]
+			if nextMove.Face() == oppositeFace(lastMove.Face()) &&
+				nextMove.Face() < lastMove.Face() {
+				allowed = false
+			}
+			if metric == STM {
+				lastAxis, _ := faceDirection(lastMove.Face())
+				nextAxis, _ := faceDirection(nextMove.Face())
+				if (len(prev.moves) == 2 || len(next.moves) == 2) &&
+					lastAxis == nextAxis {
+					allowed = false
+				} else if isSlicePair(lastMove, nextMove) {
+					allowed = false
+				}
+			}
+			res.allowed[i+1][j] = allowed
+		}
+	}
+	return res
+}
+
+// costs returns the cost of each search move, or nil if every cost is 1.
+func (m *moveSet) costs() []uint8 {
+	var res []uint8
+	unit := true
+	for _, move := range m.moves {
+		res = append(res, uint8(move.cost))
+		if move.cost != 1 {
+			unit = false
+		}
+	}
+	if unit {
+		return nil
+	}
+	return res
+}
+
+// weighted returns a moveSet in which each search move costs the total cost
+// of its moves, given a cost for each Move. Search moves which include a move
+// with a negative cost are removed.
+//
+// The heuristics for m count search moves, so they are scaled by the cost of
+// the cheapest search move.
+func (m *moveSet) weighted(costs []int) *moveSet {
+	res := &moveSet{base: m.base}
+	var kept []int
+	for i, move := range m.moves {
+		cost := 0
+		for _, b := range move.moves {
+			c := costs[m.base[b]]
+			if c < 0 {
+				cost = -1
+				break
+			}
+			cost += c
+		}
+		if cost < 0 {
+			continue
+		}
+		kept = append(kept, i)
+		res.moves = append(res.moves, searchMove{moves: move.moves, cost: cost})
+		if res.scale == 0 || cost < res.scale {
+			res.scale = cost
+		}
+	}
+	res.allowed = make([][]bool, len(kept)+1)
+	for i := range res.allowed {
+		prev := 0
+		if i > 0 {
+			prev = kept[i-1] + 1
+		}
+		res.allowed[i] = make([]bool, len(kept))
+		for j, k := range kept {
+			res.allowed[i][j] = m.allowed[prev][k]
+		}
+	}
+	return res
+}
+
+// phase1MoveSet returns the moveSet for phase-1 in a metric, where base moves
+// are Moves.
+func phase1MoveSet(metric Metric) *moveSet {
+	base := make([]Move, 18)
+	for i := range base {
+		base[i] = Move(i)
+	}
+	return newMoveSet(metric, base)
+}
+
+// phase2MoveSet returns the moveSet for phase-2 in a metric, where base moves
+// are Phase2Moves on a given axis.
+func phase2MoveSet(metric Metric, axis int) *moveSet {
+	base := make([]Move, 10)
+	for i := range base {
+		base[i] = Phase2Move(i).Move(axis)
+	}
+	return newMoveSet(metric, base)
+}
@@ -0,0 +1,191 @@
+package gocube
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMetricLength(t *testing.T) {
+	tests := []struct {
+		moves string
+		htm   int
+		qtm   int
+		stm   int
+	}{
+		{"R U2 F'", 3, 4, 3},
+		{"R L' U", 3, 3, 2},
+		{"R L U", 3, 3, 3},
+		{"R2 L2 U D'", 4, 6, 2},
+		{"R L' L", 3, 3, 2},
+	}
+	for _, test := range tests {
+		moves, err := ParseMoves(test.moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for metric, expected := range []int{test.htm, test.qtm, test.stm} {
+			if actual := Metric(metric).Length(moves); actual != expected {
+				t.Errorf("%q: expected %s length %d but got %d", test.moves,
+					Metric(metric), expected, actual)
+			}
+		}
+	}
+}
+
+func TestMoveSet(t *testing.T) {
+	counts := []struct {
+		set      *moveSet
+		expected int
+	}{
+		{phase1MoveSet(HTM), 18},
+		{phase1MoveSet(QTM), 18},
+		{phase1MoveSet(STM), 27},
+		{phase2MoveSet(HTM, 1), 10},
+		{phase2MoveSet(STM, 1), 15},
+	}
+	for i, c := range counts {
+		if len(c.set.moves) != c.expected {
+			t.Errorf("set %d: expected %d moves but got %d", i, c.expected,
+				len(c.set.moves))
+		}
+	}
+
+	set := phase1MoveSet(STM)
+	find := func(moves string) int {
+		parsed, _ := ParseMoves(moves)
+		for i, m := range set.moves {
+			if len(m.moves) != len(parsed) {
+				continue
+			}
+			match := true
+			for j, x := range m.moves {
+				if Move(x) != parsed[j] {
+					match = false
+				}
+			}
+			if match {
+				return i
+			}
+		}
+		t.Fatalf("missing search move: %s", moves)
+		return -1
+	}
+	allowed := []struct {
+		prev     string
+		next     string
+		expected bool
+	}{
+		{"R", "R2", false},
+		{"R", "L", true},
+		{"L", "R", false},
+		{"D", "U2", false},
+		{"R", "L'", false},
+		{"U D'", "U", false},
+		{"U D'", "R", true},
+		{"R", "U D'", true},
+	}
+	for _, a := range allowed {
+		if set.allowed[find(a.prev)+1][find(a.next)] != a.expected {
+			t.Errorf("%s then %s: expected allowed=%v", a.prev, a.next,
+				a.expected)
+		}
+	}
+}
+
+func TestMoveSetSequences(t *testing.T) {
+	// These are the numbers of distinct sequences of each length in HTM once
+	// turns of the same face are merged and turns of opposite faces are put
+	// in order, as counted by Korf in 1997.
+	set := phase1MoveSet(HTM)
+	counts := map[int]int{0: 1}
+	for _, expected := range []int{18, 243, 3240} {
+		next := map[int]int{}
+		for last, count := range counts {
+			for m, allowed := range set.allowed[last] {
+				if allowed {
+					next[m+1] += count
+				}
+			}
+		}
+		var total int
+		for _, count := range next {
+			total += count
+		}
+		if total != expected {
+			t.Errorf("expected %d sequences but got %d", expected, total)
+		}
+		counts = next
+	}
+}
+
+func TestSolveMetric(t *testing.T) {
+	scrambles := map[Metric]string{
+		QTM: "R U2 F' D",
+		STM: "R L' U D' F2 B2",
+	}
+	for metric, scramble := range scrambles {
+		tables := sharedSolverTables(metric)
+		cube := SolvedCubieCube()
+		moves, _ := ParseMoves(scramble)
+		for _, m := range moves {
+			cube.Move(m)
+		}
+		res := Solve(context.Background(), cube, SolveOptions{
+			Metric: metric,
+			Tables: tables,
+		})
+		if res.Reason != StopExhausted {
+			t.Errorf("%s: unexpected reason: %s", metric, res.Reason)
+		}
+		if metric.Length(res.Solution) != metric.Length(moves) {
+			t.Errorf("%s: unexpected solution: %v", metric, res.Solution)
+		}
+		for _, m := range res.Solution {
+			cube.Move(m)
+		}
+		if !cube.Solved() {
+			t.Errorf("%s: solution %v does not work", metric, res.Solution)
+		}
+	}
+}
+
+func TestMoveSetWeighted(t *testing.T) {
+	costs := make([]int, 18)
+	for m := range costs {
+		costs[m] = 2
+		if Move(m).Face() == 4 {
+			costs[m] = ForbiddenMove
+		} else if Move(m).Turns() == 2 {
+			costs[m] = 3
+		}
+	}
+	full := phase1MoveSet(HTM)
+	set := full.weighted(costs)
+	if len(set.moves) != 15 {
+		t.Fatalf("expected 15 moves but got %d", len(set.moves))
+	}
+	if set.scale != 2 {
+		t.Errorf("expected scale 2 but got %d", set.scale)
+	}
+	index := func(s *moveSet, m Move) int {
+		for i, x := range s.moves {
+			if Move(x.moves[0]) == m {
+				return i
+			}
+		}
+		return -1
+	}
+	for i, x := range set.moves {
+		m := Move(x.moves[0])
+		if x.cost != costs[m] {
+			t.Errorf("move %s: expected cost %d but got %d", m, costs[m], x.cost)
+		}
+		for j, y := range set.moves {
+			expected := full.allowed[index(full, m)+1][index(full, Move(y.moves[0]))]
+			if set.allowed[i+1][j] != expected {
+				t.Errorf("%s then %s: expected allowed=%v", m, Move(y.moves[0]),
+					expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package gocube
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// netRows lists the faces in each row of faces in an unfolded net.
+var netRows = [][]int{{1}, {6, 3, 5, 4}, {2}}
+
+// ansiColors contains the escape codes used to draw each sticker color.
+var ansiColors = []string{
+	"",
+	"\x1b[30;107m",
+	"\x1b[30;103m",
+	"\x1b[30;42m",
+	"\x1b[97;44m",
+	"\x1b[97;41m",
+	"\x1b[30;48;5;208m",
+}
+
+const ansiReset = "\x1b[0m"
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Net draws the stickers as an unfolded net, using a letter for each color.
+//
+// The U face is drawn above the F face and the D face is drawn below it. The
+// middle row shows L, F, R, and B from left to right. Each face is drawn as it
+// is read in a StickerCube, so the net can be folded into a cube.
+func (s *StickerCube) Net() string {
+	return s.net(false)
+}
+
+// ANSINet is like Net, but it uses ANSI escape codes to color each sticker.
+//
+// The letters are kept in the output, so a net copied from a terminal can still
+// be parsed with ParseNet.
+func (s *StickerCube) ANSINet() string {
+	return s.net(true)
+}
+
+func (s *StickerCube) net(color bool) string {
+	var res strings.Builder
+	for _, faces := range netRows {
+		for row := 0; row < 3; row++ {
+			if len(faces) == 1 {
+				res.WriteString(strings.Repeat(" ", netFaceWidth(color)+1))
+			}
+			for i, face := range faces {
+				if i > 0 {
+					res.WriteByte(' ')
+				}
+				for col := 0; col < 3; col++ {
+					sticker := s[(face-1)*9+row*3+col]
+					letter := stickerLetters([]int{sticker})
+					if color && sticker >= 1 && sticker <= 6 {
+						res.WriteString(ansiColors[sticker] + " " + letter + " " +
+							ansiReset)
+					} else {
+						res.WriteString(letter)
+					}
+				}
+			}
+			res.WriteByte('\n')
+		}
+	}
+	return res.String()
+}
+
+func netFaceWidth(color bool) int {
+	if color {
+		return 9
+	}
+	return 3
+}
+
+// ParseNet parses an unfolded net like the ones generated by Net and ANSINet.
+//
+// Whitespace, blank lines and ANSI escape codes are ignored. Stickers may be
+// given as color letters or digits, like in ParseStickerCube.
+//
+// The net is not validated beyond checking its shape and its characters.
+func ParseNet(str string) (*StickerCube, error) {
+	var rows []string
+	for _, line := range strings.Split(str, "\n") {
+		if row := netRow(line); row != "" {
+			rows = append(rows, row)
+		}
+	}
+	return parseNetRows(rows)
+}
+
+// ReadNet reads an unfolded net from a reader, stopping after the last row of
+// the net. See ParseNet for details on the format.
+func ReadNet(r io.Reader) (*StickerCube, error) {
+	scanner := bufio.NewScanner(r)
+	var rows []string
+	for len(rows) < 9 && scanner.Scan() {
+		if row := netRow(scanner.Text()); row != "" {
+			rows = append(rows, row)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return parseNetRows(rows)
+}
+
+func netRow(line string) string {
+	line = ansiEscape.ReplaceAllString(line, "")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+func parseNetRows(rows []string) (*StickerCube, error) {
+	if len(rows) != 9 {
+		return nil, fmt.Errorf("expected 9 rows but got %d", len(rows))
+	}
+	var res StickerCube
+	for i, row := range rows {
+		faces := netRows[i/3]
+		runes := []rune(row)
+		if len(runes) != len(faces)*3 {
+			return nil, fmt.Errorf("row %d should have %d stickers but has %d",
+				i+1, len(faces)*3, len(runes))
+		}
+		for j, c := range runes {
+			color := stickerColor(c)
+			if color == 0 {
+				return nil, errors.New("unexpected character: " + string(c))
+			}
+			face := faces[j/3]
+			res[(face-1)*9+(i%3)*3+j%3] = color
+		}
+	}
+	return &res, nil
+}
@@ -0,0 +1,59 @@
+package gocube
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStickerCubeNet(t *testing.T) {
+	cube := SolvedCubieCube()
+	cube.Move(NewMove(1, 1))
+	stickers := cube.StickerCube()
+	expected := "    WWW\n" +
+		"    WWW\n" +
+		"    WWW\n" +
+		"GGG RRR BBB OOO\n" +
+		"OOO GGG RRR BBB\n" +
+		"OOO GGG RRR BBB\n" +
+		"    YYY\n" +
+		"    YYY\n" +
+		"    YYY\n"
+	if net := stickers.Net(); net != expected {
+		t.Errorf("unexpected net:\n%s", net)
+	}
+}
+
+func TestParseNet(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		stickers := cube.StickerCube()
+		for _, net := range []string{stickers.Net(), stickers.ANSINet(),
+			"\n\n" + strings.Replace(stickers.Net(), "\n", "\n\n", -1)} {
+			parsed, err := ParseNet(net)
+			if err != nil {
+				t.Fatal(err)
+			} else if *parsed != stickers {
+				t.Errorf("net did not round trip:\n%s", net)
+			}
+			parsed, err = ReadNet(strings.NewReader(net + "extra input\n"))
+			if err != nil {
+				t.Fatal(err)
+			} else if *parsed != stickers {
+				t.Errorf("net could not be read:\n%s", net)
+			}
+		}
+	}
+
+	solved := SolvedStickerCube()
+	invalid := []string{
+		"",
+		strings.Replace(solved.Net(), "W", "X", 1),
+		strings.Replace(solved.Net(), "OOO GGG", "OO GGG", 1),
+		strings.Replace(solved.Net(), "    YYY\n", "", 1),
+	}
+	for _, net := range invalid {
+		if _, err := ParseNet(net); err == nil {
+			t.Errorf("expected error for net:\n%s", net)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package gocube
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A ParseError describes a syntax error in algorithm notation.
+type ParseError struct {
+	// Offset is the byte offset of the error in the input.
+	Offset int
+
+	// Line and Column are the 1-based line and column (in characters) of the
+	// error in the input.
+	Line   int
+	Column int
+
+	Message string
+}
+
+func (p *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", p.Line, p.Column, p.Message)
+}
+
+// maxRepetitions is the largest number which ParseAlgorithm accepts, whether
+// it repeats a group or a turn.
+const maxRepetitions = 500
+
+// maxParsedTurns is the length of the longest algorithm which ParseAlgorithm
+// builds by repeating groups or expanding brackets.
+const maxParsedTurns = 100000
+
+// ParseAlgorithm parses an algorithm in WCA or SiGN notation.
+//
+// In addition to the 18 face turns, this supports slices (M, E, S), wide turns
+// (Rw, r, 2Rw), inner layers (2R), rotations (x, y, z), arbitrary amounts
+// (R3), groups with repetition ((R U R' U')3), commutators ([A, B]),
+// conjugates ([A: B]), and comments (// and /* */).
+//
+// Any amount of whitespace may separate turns. Amounts and repetition counts
+// may be at most 500, and the expanded algorithm may have at most 100000
+// turns. If the input is malformed, the returned error is a *ParseError.
+func ParseAlgorithm(s string) (Algorithm, error) {
+	p := &algorithmParser{input: []rune(s)}
+	res, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+	return res, nil
+}
+
+type algorithmParser struct {
+	input []rune
+	pos   int
+}
+
+// parseSequence parses turns and groups until the end of the input or a
+// closing delimiter.
+func (p *algorithmParser) parseSequence() (Algorithm, error) {
+	var res Algorithm
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos == len(p.input) {
+			return res, nil
+		}
+		switch p.input[p.pos] {
+		case ')', ']', ',', ':':
+			return res, nil
+		case '(':
+			start := p.pos
+			p.pos++
+			group, err := p.parseSequence()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(')', start, "unclosed parenthesis"); err != nil {
+				return nil, err
+			}
+			group, err = p.parseRepetition(group, start)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, group...)
+		case '[':
+			start := p.pos
+			p.pos++
+			group, err := p.parseBrackets(start)
+			if err != nil {
+				return nil, err
+			}
+			group, err = p.parseRepetition(group, start)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, group...)
+		default:
+			turn, ok, err := p.parseTurn()
+			if err != nil {
+				return nil, err
+			} else if ok {
+				res = append(res, turn)
+			}
+		}
+	}
+}
+
+// parseBrackets parses the inside of a commutator or conjugate, including the
+// closing bracket.
+func (p *algorithmParser) parseBrackets(start int) (Algorithm, error) {
+	first, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos == len(p.input) {
+		return nil, p.errorAt(start, "unclosed bracket")
+	}
+	separator := p.input[p.pos]
+	if separator != ',' && separator != ':' {
+		return nil, p.errorf("expected ',' or ':'")
+	}
+	p.pos++
+	second, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(']', start, "unclosed bracket"); err != nil {
+		return nil, err
+	}
+
+	if 2*(len(first)+len(second)) > maxParsedTurns {
+		return nil, p.errorAt(start, "algorithm is too long")
+	}
+	res := append(Algorithm{}, first...)
+	res = append(res, second...)
+	res = append(res, first.Inverse()...)
+	if separator == ',' {
+		res = append(res, second.Inverse()...)
+	}
+	return res, nil
+}
+
+// parseRepetition parses an optional count and prime after a group which
+// started at a given position.
+func (p *algorithmParser) parseRepetition(group Algorithm,
+	start int) (Algorithm, error) {
+	count, err := p.parseNumber(1)
+	if err != nil {
+		return nil, err
+	} else if count*len(group) > maxParsedTurns {
+		return nil, p.errorAt(start, "algorithm is too long")
+	}
+	if p.parsePrime() {
+		group = group.Inverse()
+	}
+	var res Algorithm
+	for i := 0; i < count; i++ {
+		res = append(res, group...)
+	}
+	return res, nil
+}
+
+// parseTurn parses a single turn. If the turn has no effect (e.g. R4), ok is
+// false.
+func (p *algorithmParser) parseTurn() (Turn, bool, error) {
+	start := p.pos
+	layer, err := p.parseNumber(0)
+	if err != nil {
+		return Turn{}, false, err
+	}
+	if p.pos == len(p.input) {
+		return Turn{}, false, p.errorAt(start, "expected turn")
+	}
+
+	letter := p.input[p.pos]
+	var kind TurnKind
+	var face int
+	if idx := strings.IndexRune("UDFBRL", letter); idx >= 0 {
+		face = idx + 1
+		kind = FaceTurn
+		if p.pos+1 < len(p.input) && p.input[p.pos+1] == 'w' {
+			kind = WideTurn
+			p.pos++
+		}
+	} else if idx := strings.IndexRune("udfbrl", letter); idx >= 0 {
+		face = idx + 1
+		kind = WideTurn
+	} else if idx := strings.IndexRune("MES", letter); idx >= 0 {
+		face = []int{6, 2, 3}[idx]
+		kind = SliceTurn
+	} else if idx := strings.IndexRune("xyz", letter); idx >= 0 {
+		face = []int{5, 1, 3}[idx]
+		kind = RotationTurn
+	} else {
+		return Turn{}, false, p.errorf("unexpected %q", letter)
+	}
+	p.pos++
+
+	// SiGN layer prefixes select which layers of a 3x3x3 are turned.
+	inverse := false
+	if layer != 0 {
+		if kind != FaceTurn && kind != WideTurn {
+			return Turn{}, false, p.errorAt(start,
+				"layer prefix on "+string(letter))
+		} else if layer > 3 {
+			return Turn{}, false, p.errorAt(start, "layer out of range")
+		}
+		if kind == FaceTurn {
+			if layer == 2 {
+				kind = SliceTurn
+			} else if layer == 3 {
+				face = oppositeFace(face)
+				inverse = true
+			}
+		} else {
+			kind = []TurnKind{FaceTurn, FaceTurn, WideTurn, RotationTurn}[layer]
+		}
+	}
+
+	amount, err := p.parseNumber(1)
+	if err != nil {
+		return Turn{}, false, err
+	}
+	amount %= 4
+	if p.parsePrime() != inverse {
+		amount = (4 - amount) % 4
+	}
+	if amount == 0 {
+		return Turn{}, false, nil
+	}
+	return NewTurn(kind, face, []int{0, 1, 2, -1}[amount]), true, nil
+}
+
+// parseNumber parses an optional decimal number, returning def if there is
+// none. Numbers larger than maxRepetitions are rejected, so that they cannot
+// overflow.
+func (p *algorithmParser) parseNumber(def int) (int, error) {
+	start := p.pos
+	res := 0
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' &&
+		p.input[p.pos] <= '9' {
+		res = res*10 + int(p.input[p.pos]-'0')
+		p.pos++
+		if res > maxRepetitions {
+			return 0, p.errorAt(start, "number is too large")
+		}
+	}
+	if p.pos == start {
+		return def, nil
+	}
+	return res, nil
+}
+
+// parsePrime parses an optional prime symbol.
+func (p *algorithmParser) parsePrime() bool {
+	if p.pos < len(p.input) && (p.input[p.pos] == '\'' ||
+		p.input[p.pos] == '’') {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *algorithmParser) skipSpace() error {
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if unicode.IsSpace(c) {
+			p.pos++
+		} else if p.hasPrefix("//") {
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		} else if p.hasPrefix("/*") {
+			start := p.pos
+			p.pos += 2
+			for !p.hasPrefix("*/") {
+				if p.pos == len(p.input) {
+					return p.errorAt(start, "unclosed comment")
+				}
+				p.pos++
+			}
+			p.pos += 2
+		} else {
+			break
+		}
+	}
+	return nil
+}
+
+func (p *algorithmParser) hasPrefix(prefix string) bool {
+	for i, c := range []rune(prefix) {
+		if p.pos+i >= len(p.input) || p.input[p.pos+i] != c {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *algorithmParser) expect(c rune, start int, message string) error {
+	if p.pos == len(p.input) {
+		return p.errorAt(start, message)
+	} else if p.input[p.pos] != c {
+		return p.errorf("expected %q but got %q", c, p.input[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+func (p *algorithmParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *algorithmParser) errorAt(pos int, message string) error {
+	line, column := 1, 1
+	for _, c := range p.input[:pos] {
+		if c == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	offset := len(string(p.input[:pos]))
+	return &ParseError{offset, line, column, message}
+}
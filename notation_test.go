@@ -0,0 +1,68 @@
+package gocube
+
+import "testing"
+
+func TestParseAlgorithm(t *testing.T) {
+	cases := map[string]string{
+		"R U R' U'":                      "R U R' U'",
+		"  R\tU2\n\nR’ U3 D4 L1":         "R U2 R' U' L",
+		"RUR'U'":                         "R U R' U'",
+		"M E' S2 x y' z2":                "M E' S2 x y' z2",
+		"Rw r' 2R 3R 2Rw 3Rw 1R 2U' 3Lw": "Rw Rw' M' L' Rw x R E x'",
+		"(R U R' U')2":                   "R U R' U' R U R' U'",
+		"(R U)'":                         "U' R'",
+		"(R U)2'":                        "U' R' U' R'",
+		"[R, U]":                         "R U R' U'",
+		"[R: U]":                         "R U R'",
+		"[R: [U, D]]":                    "R U D U' D' R'",
+		"[[R: U], D]2":                   "R U R' D R U' R' D' R U R' D R U' R' D'",
+		"R // sexy move\nU /* block\ncomment */ R'": "R U R'",
+		"": "",
+	}
+	for input, expected := range cases {
+		alg, err := ParseAlgorithm(input)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", input, err)
+		} else if alg.String() != expected {
+			t.Errorf("parsed %q as %q (expected %q)", input, alg.String(),
+				expected)
+		}
+	}
+}
+
+func TestParseAlgorithmErrors(t *testing.T) {
+	cases := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"R U Q", 1, 5},
+		{"R U\n  R' (U", 2, 6},
+		{"[R U]", 1, 5},
+		{"[R, U", 1, 1},
+		{"R U)", 1, 4},
+		{"2M", 1, 1},
+		{"4R", 1, 1},
+		{"R /* U", 1, 3},
+		{"(R, U)", 1, 3},
+		{"R2'2 3", 1, 5},
+		{"(R U)999999999", 1, 6},
+		{"R U (R U)501", 1, 10},
+		{"R99999999999999999999", 1, 2},
+		{"((((R U)500)500)2)", 1, 3},
+	}
+	for _, c := range cases {
+		_, err := ParseAlgorithm(c.input)
+		if err == nil {
+			t.Errorf("expected error for %q", c.input)
+			continue
+		}
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("unexpected error type for %q: %v", c.input, err)
+		} else if parseErr.Line != c.line || parseErr.Column != c.column {
+			t.Errorf("error for %q at %d:%d (expected %d:%d): %v", c.input,
+				parseErr.Line, parseErr.Column, c.line, c.column, err)
+		}
+	}
+}
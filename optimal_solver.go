@@ -0,0 +1,279 @@
+package gocube
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// OptimalTables stores the pattern databases used by an OptimalSolver.
+//
+// The lower bound for a state is the largest value from any of the databases.
+// Any database may be omitted, at the cost of a weaker lower bound.
+type OptimalTables struct {
+	Corners *CornerPatternDatabase
+	Edges   []*EdgePatternDatabase
+}
+
+// NewOptimalTables generates the standard tables for optimal solving: the
+// corner database, plus two databases with six edges each.
+//
+// This takes a while and uses about 100MB of memory, with a peak of about
+// 180MB while the edge databases are generated.
+func NewOptimalTables() *OptimalTables {
+	return &OptimalTables{
+		Corners: NewCornerPatternDatabase(),
+		Edges: []*EdgePatternDatabase{
+			NewEdgePatternDatabase([]int{0, 1, 2, 3, 4, 5}),
+			NewEdgePatternDatabase([]int{6, 7, 8, 9, 10, 11}),
+		},
+	}
+}
+
+// LowerBound returns the minimum number of moves needed to solve a cube.
+func (o *OptimalTables) LowerBound(c *CubieCube) int {
+	node := newOptimalNode(c, o.Corners)
+	return o.nodeLowerBound(&node)
+}
+
+func (o *OptimalTables) nodeLowerBound(n *optimalNode) int {
+	var res int
+	if o.Corners != nil {
+		res = int(o.Corners.db.data.get(n.corners))
+	}
+	for _, edges := range o.Edges {
+		if bound := edges.lookupPositions(&n.edgePositions,
+			n.edgeFlips); bound > res {
+			res = bound
+		}
+	}
+	return res
+}
+
+// An OptimalSolver finds the shortest solutions to a cube using iterative
+// deepening A* search.
+type OptimalSolver struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	solutions  chan []Move
+	lowerBound int32
+	reason     int32
+
+	cube     CubieCube
+	tables   *OptimalTables
+	observer *PhaseObserver
+}
+
+// NewOptimalSolver creates and starts an OptimalSolver.
+func NewOptimalSolver(c CubieCube, tables *OptimalTables) *OptimalSolver {
+	return NewOptimalSolverContext(context.Background(), c, tables, nil)
+}
+
+// NewOptimalSolverContext creates and starts an OptimalSolver which stops
+// when ctx is done.
+//
+// If observer is non-nil, the search reports to its phase 0. A new depth is
+// started once every shorter solution has been ruled out, so OnDepth reports
+// each completed depth as it happens.
+func NewOptimalSolverContext(ctx context.Context, c CubieCube,
+	tables *OptimalTables, observer *SearchObserver) *OptimalSolver {
+	ctx, cancel := context.WithCancel(ctx)
+	res := &OptimalSolver{
+		ctx:       ctx,
+		cancel:    cancel,
+		solutions: make(chan []Move),
+		cube:      c,
+		tables:    tables,
+		observer:  observer.Phase(0),
+	}
+	go res.search()
+	return res
+}
+
+// SolveOptimal finds one of the shortest solutions to a cube, blocking until
+// it is found.
+func SolveOptimal(c CubieCube, tables *OptimalTables) []Move {
+	solver := NewOptimalSolver(c, tables)
+	solution := <-solver.Solutions()
+	solver.Stop()
+	return solution
+}
+
+// Solutions is a channel over which every optimal solution is delivered. The
+// channel is closed once every solution of the optimal length has been found,
+// or when the solver is stopped.
+func (o *OptimalSolver) Solutions() <-chan []Move {
+	return o.solutions
+}
+
+// LowerBound returns the minimum length of a solution, based on the search
+// depths which have been exhausted so far.
+//
+// This can be called at any time, so a caller who stops the search early still
+// learns how long the optimal solution must be.
+func (o *OptimalSolver) LowerBound() int {
+	return int(atomic.LoadInt32(&o.lowerBound))
+}
+
+// Stop stops the solver. It may be called more than once.
+func (o *OptimalSolver) Stop() {
+	o.cancel()
+}
+
+// Reason returns the reason that the solver stopped. It is StopExhausted if
+// every optimal solution was found, and StopCanceled if the solver was stopped
+// first. It returns StopNone until the Solutions channel has been closed.
+func (o *OptimalSolver) Reason() StopReason {
+	return StopReason(atomic.LoadInt32(&o.reason))
+}
+
+func (o *OptimalSolver) search() {
+	reason := StopCanceled
+	defer func() {
+		atomic.StoreInt32(&o.reason, int32(reason))
+		o.cancel()
+		close(o.solutions)
+	}()
+	defer o.observer.EndSearch(o.observer.StartSearch())
+
+	start := newOptimalNode(&o.cube, o.tables.Corners)
+	depth := o.tables.nodeLowerBound(&start)
+	for {
+		atomic.StoreInt32(&o.lowerBound, int32(depth))
+		o.observer.StartDepth(depth)
+		moves := make([]Move, 0, depth)
+		found, ok := o.depthFirst(start, moves, depth, 0)
+		if !ok {
+			return
+		} else if found {
+			reason = StopExhausted
+			return
+		}
+		depth++
+	}
+}
+
+// depthFirst searches for solutions of a given length. It returns whether any
+// solutions were found, and false for ok if the search was stopped.
+func (o *OptimalSolver) depthFirst(n optimalNode, moves []Move, depth,
+	lastFace int) (found, ok bool) {
+	o.observer.Node()
+	if depth == 0 {
+		if !n.solved(o.tables.Corners) {
+			return false, true
+		}
+		res := make([]Move, len(moves))
+		copy(res, moves)
+		select {
+		case <-o.ctx.Done():
+			return true, false
+		case o.solutions <- res:
+		}
+		return true, true
+	}
+
+	if o.tables.nodeLowerBound(&n) > depth {
+		o.observer.Prune()
+		return false, true
+	}
+
+	for m := 0; m < 18; m++ {
+		move := Move(m)
+		face := move.Face()
+		// Turns of opposite faces commute, so they are only searched in one
+		// order.
+		if face == lastFace ||
+			(face == oppositeFace(lastFace) && face < lastFace) {
+			continue
+		}
+		next := n
+		next.move(move, o.tables.Corners)
+		subFound, subOK := o.depthFirst(next, append(moves, move), depth-1,
+			face)
+		found = found || subFound
+		if !subOK {
+			return found, false
+		}
+		if depth >= 6 && o.isStopped() {
+			return found, false
+		}
+	}
+	return found, true
+}
+
+func (o *OptimalSolver) isStopped() bool {
+	return o.ctx.Err() != nil
+}
+
+// An optimalNode stores the state of a cube in the form which is used to look
+// up its lower bound.
+type optimalNode struct {
+	// corners is the index of the corners in the CornerPatternDatabase. When
+	// there is no database, cornerPositions and cornerTwists store the slot
+	// and twist of each corner piece instead.
+	corners         int
+	cornerPositions [8]int8
+	cornerTwists    [8]int8
+
+	// edgePositions stores the slot of each edge piece, and edgeFlips has a
+	// bit set for each flipped piece.
+	edgePositions [12]int8
+	edgeFlips     uint16
+}
+
+func newOptimalNode(c *CubieCube, corners *CornerPatternDatabase) optimalNode {
+	var res optimalNode
+	if corners != nil {
+		res.corners = corners.db.cornerIndex(&c.Corners)
+	} else {
+		for slot, corner := range c.Corners {
+			res.cornerPositions[corner.Piece] = int8(slot)
+			res.cornerTwists[corner.Piece] = int8(c.Corners.Twist(slot))
+		}
+	}
+	for slot, edge := range c.Edges {
+		res.edgePositions[edge.Piece] = int8(slot)
+		if edge.Flip {
+			res.edgeFlips |= 1 << uint(edge.Piece)
+		}
+	}
+	return res
+}
+
+func (n *optimalNode) move(m Move, corners *CornerPatternDatabase) {
+	if corners != nil {
+		n.corners = corners.db.corners.move(n.corners, int(m))
+	} else {
+		for piece, slot := range n.cornerPositions {
+			n.cornerTwists[piece] = (n.cornerTwists[piece] +
+				cornerTwistMoves[m][slot]) % 3
+			n.cornerPositions[piece] = cornerSlotMoves[m][slot]
+		}
+	}
+	for piece, slot := range n.edgePositions {
+		if edgeFlipMoves[m][slot] {
+			n.edgeFlips ^= 1 << uint(piece)
+		}
+		n.edgePositions[piece] = edgeSlotMoves[m][slot]
+	}
+}
+
+// solved checks if a node is the solved state.
+func (n *optimalNode) solved(corners *CornerPatternDatabase) bool {
+	if n.edgeFlips != 0 {
+		return false
+	}
+	for piece, slot := range n.edgePositions {
+		if int(slot) != piece {
+			return false
+		}
+	}
+	if corners != nil {
+		return n.corners == corners.db.corners.solved
+	}
+	for piece, slot := range n.cornerPositions {
+		if int(slot) != piece || n.cornerTwists[piece] != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package gocube
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func smallOptimalTables() *OptimalTables {
+	return &OptimalTables{
+		Edges: []*EdgePatternDatabase{
+			NewEdgePatternDatabase([]int{0, 1, 2, 3}),
+			NewEdgePatternDatabase([]int{4, 5, 6, 7}),
+			NewEdgePatternDatabase([]int{8, 9, 10, 11}),
+		},
+	}
+}
+
+func TestOptimalSolver(t *testing.T) {
+	tables := smallOptimalTables()
+	scrambles := []struct {
+		moves   string
+		optimal int
+	}{
+		{"", 0},
+		{"R U", 2},
+		{"R L U2 R' L'", 5},
+		{"F R U R' U' F'", 6},
+		{"R U R' U R U2 R'", 7},
+	}
+	for _, scramble := range scrambles {
+		moves, _ := ParseMoves(scramble.moves)
+		cube := SolvedCubieCube()
+		for _, m := range moves {
+			cube.Move(m)
+		}
+
+		solver := NewOptimalSolver(cube, tables)
+		solution, ok := <-solver.Solutions()
+		if !ok {
+			t.Fatalf("%s: no solution", scramble.moves)
+		}
+		if bound := solver.LowerBound(); bound != scramble.optimal {
+			t.Errorf("%s: lower bound %d", scramble.moves, bound)
+		}
+		solver.Stop()
+
+		if len(solution) != scramble.optimal {
+			t.Errorf("%s: expected %d moves but got %v", scramble.moves,
+				scramble.optimal, solution)
+		}
+		for _, m := range solution {
+			cube.Move(m)
+		}
+		if !cube.Solved() {
+			t.Errorf("%s: solution %v does not solve the cube", scramble.moves,
+				solution)
+		}
+	}
+}
+
+func TestOptimalSolverAllSolutions(t *testing.T) {
+	// R2 L2 can also be solved as L2 R2, but only one order is searched.
+	cube := SolvedCubieCube()
+	cube.Move(NewMove(5, 2))
+	cube.Move(NewMove(6, 2))
+	solver := NewOptimalSolver(cube, smallOptimalTables())
+	var count int
+	for solution := range solver.Solutions() {
+		if len(solution) != 2 {
+			t.Errorf("unexpected solution %v", solution)
+		}
+		count++
+	}
+	if count != 1 {
+		t.Errorf("expected 1 solution but got %d", count)
+	}
+}
+
+func TestOptimalSolverContext(t *testing.T) {
+	tables := smallOptimalTables()
+
+	t.Run("Depths", func(t *testing.T) {
+		moves, _ := ParseMoves("R U R' U R U2 R'")
+		cube := SolvedCubieCube()
+		for _, m := range moves {
+			cube.Move(m)
+		}
+		var lock sync.Mutex
+		var depths []int
+		observer := &SearchObserver{}
+		observer.OnDepth = func(phase, depth int) {
+			lock.Lock()
+			depths = append(depths, depth)
+			lock.Unlock()
+		}
+		solver := NewOptimalSolverContext(context.Background(), cube, tables,
+			observer)
+		for solution := range solver.Solutions() {
+			if len(solution) != 7 {
+				t.Errorf("unexpected solution %v", solution)
+			}
+		}
+		if solver.Reason() != StopExhausted {
+			t.Errorf("unexpected reason: %s", solver.Reason())
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if len(depths) == 0 || depths[len(depths)-1] != 7 {
+			t.Fatalf("unexpected depths %v", depths)
+		}
+		for i := 1; i < len(depths); i++ {
+			if depths[i] != depths[i-1]+1 {
+				t.Errorf("unexpected depths %v", depths)
+			}
+		}
+		if stats := observer.Stats(); stats[0].Nodes == 0 ||
+			stats[0].Searches != 1 {
+			t.Errorf("unexpected stats %+v", stats[0])
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		solver := NewOptimalSolverContext(ctx, RandomCubieCube(), tables, nil)
+		for solution := range solver.Solutions() {
+			t.Errorf("unexpected solution %v", solution)
+		}
+		if solver.Reason() != StopCanceled {
+			t.Errorf("unexpected reason: %s", solver.Reason())
+		}
+	})
+}
+
+func TestOptimalTablesLowerBound(t *testing.T) {
+	tables := smallOptimalTables()
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		bound := tables.LowerBound(&cube)
+		if bound < 5 || bound > 20 {
+			t.Errorf("unlikely lower bound %d for random state", bound)
+		}
+	}
+}
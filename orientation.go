@@ -0,0 +1,108 @@
+package gocube
+
+// An Orientation describes how a cube has been rotated in space. There are 24
+// possible orientations.
+//
+// Each entry corresponds to a position (U, D, F, B, R, and L respectively) and
+// stores the face (a number from 1 through 6, as in Move.Face()) whose center
+// is currently in that position. For example, after an x rotation, the F
+// center is on top, so the first entry is 3.
+type Orientation [6]int
+
+// StandardOrientation returns the orientation of a cube which has not been
+// rotated.
+func StandardOrientation() Orientation {
+	return Orientation{1, 2, 3, 4, 5, 6}
+}
+
+// Rotate applies a rotation to the orientation.
+func (o *Orientation) Rotate(r Rotation) {
+	var applyCount int
+	switch r.Turns() {
+	case 1:
+		applyCount = 1
+	case 2:
+		applyCount = 2
+	case -1:
+		applyCount = 3
+	}
+
+	// Each cycle lists the positions whose centers are moved to the previous
+	// position in the cycle.
+	cycle := [][4]int{
+		{0, 2, 1, 3},
+		{2, 4, 3, 5},
+		{0, 5, 1, 4},
+	}[r.Axis()]
+
+	for i := 0; i < applyCount; i++ {
+		o[cycle[0]], o[cycle[1]], o[cycle[2]], o[cycle[3]] =
+			o[cycle[1]], o[cycle[2]], o[cycle[3]], o[cycle[0]]
+	}
+}
+
+// Move translates a move of the cube in this orientation to a move of the cube
+// in the standard orientation.
+//
+// For example, if the cube has been rotated by x, then turning the U face is
+// really turning the F face.
+func (o Orientation) Move(m Move) Move {
+	return NewMove(o[m.Face()-1], m.Turns())
+}
+
+// Position returns the position (a number from 1 through 6) where a given face
+// is located.
+func (o Orientation) Position(face int) int {
+	for i, f := range o {
+		if f == face {
+			return i + 1
+		}
+	}
+	panic("invalid orientation")
+}
+
+// Rotations returns a shortest sequence of rotations which takes the standard
+// orientation to this orientation.
+//
+// This panics if o is not one of the 24 valid orientations.
+func (o Orientation) Rotations() []Rotation {
+	res, ok := o.rotations()
+	if !ok {
+		panic("invalid orientation")
+	}
+	return res
+}
+
+// Valid returns true if o is one of the 24 orientations which can be reached
+// by rotating the standard orientation.
+func (o Orientation) Valid() bool {
+	_, ok := o.rotations()
+	return ok
+}
+
+func (o Orientation) rotations() ([]Rotation, bool) {
+	type node struct {
+		orientation Orientation
+		rotations   []Rotation
+	}
+	queue := []node{{StandardOrientation(), nil}}
+	visited := map[Orientation]bool{StandardOrientation(): true}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.orientation == o {
+			return n.rotations, true
+		}
+		for r := Rotation(0); r < 9; r++ {
+			next := n.orientation
+			next.Rotate(r)
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			rotations := append(append([]Rotation{}, n.rotations...), r)
+			queue = append(queue, node{next, rotations})
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,57 @@
+package gocube
+
+import "testing"
+
+func TestOrientationRotate(t *testing.T) {
+	for r := Rotation(0); r < 9; r++ {
+		stickers := SolvedStickerCube()
+		stickers.Rotate(r)
+		orientation := StandardOrientation()
+		orientation.Rotate(r)
+		for pos := 1; pos <= 6; pos++ {
+			if center := stickers[(pos-1)*9+4]; center != orientation[pos-1] {
+				t.Errorf("rotation %s: expected %d at position %d but got %d",
+					r, center, pos, orientation[pos-1])
+			}
+		}
+	}
+}
+
+func TestOrientationRotations(t *testing.T) {
+	seen := map[Orientation]bool{}
+	queue := []Orientation{StandardOrientation()}
+	for len(queue) > 0 {
+		o := queue[0]
+		queue = queue[1:]
+		if seen[o] {
+			continue
+		}
+		seen[o] = true
+
+		rotations := o.Rotations()
+		if len(rotations) > 2 {
+			t.Errorf("%v needs %d rotations", o, len(rotations))
+		}
+		actual := StandardOrientation()
+		for _, r := range rotations {
+			actual.Rotate(r)
+		}
+		if actual != o {
+			t.Errorf("rotations %v give %v (expected %v)", rotations, actual, o)
+		}
+		for face := 1; face <= 6; face++ {
+			if o[o.Position(face)-1] != face {
+				t.Errorf("bad position for face %d in %v", face, o)
+			}
+		}
+
+		for r := Rotation(0); r < 9; r++ {
+			next := o
+			next.Rotate(r)
+			queue = append(queue, next)
+		}
+	}
+	if len(seen) != 24 {
+		t.Errorf("expected 24 orientations but got %d", len(seen))
+	}
+}
@@ -0,0 +1,106 @@
+package gocube
+
+import "errors"
+
+// errImpossibleCenters indicates that an orientation is not one of the 24
+// which can be reached by rotating a cube.
+var errImpossibleCenters = errors.New("impossible center arrangement")
+
+// An OrientedCube is a CubieCube whose centers may have been rotated in space.
+//
+// The Cube field describes the pieces relative to the centers, exactly as if
+// the cube were held in the standard orientation. The Orientation field tracks
+// where the centers are. This makes it possible to apply rotations, slices and
+// wide turns without converting to stickers.
+type OrientedCube struct {
+	Cube        CubieCube
+	Orientation Orientation
+}
+
+// SolvedOrientedCube returns a solved cube in the standard orientation.
+func SolvedOrientedCube() OrientedCube {
+	return OrientedCube{SolvedCubieCube(), StandardOrientation()}
+}
+
+// Move applies a face turn to the face which is currently in a given position.
+// For example, after an x rotation, U turns the F face.
+func (o *OrientedCube) Move(m Move) {
+	o.Cube.Move(o.Orientation.Move(m))
+}
+
+// Rotate rotates the entire cube.
+func (o *OrientedCube) Rotate(r Rotation) {
+	o.Orientation.Rotate(r)
+}
+
+// Turn applies a turn, which may be a slice, wide turn, or rotation.
+func (o *OrientedCube) Turn(t Turn) {
+	Algorithm{t}.Apply(&o.Cube, &o.Orientation)
+}
+
+// Apply applies an algorithm to the cube.
+func (o *OrientedCube) Apply(a Algorithm) {
+	a.Apply(&o.Cube, &o.Orientation)
+}
+
+// Solved returns true if the cube is solved in any orientation.
+func (o *OrientedCube) Solved() bool {
+	return o.Cube.Solved()
+}
+
+// Validate checks that the cube could be reached from a solved cube by turns
+// and rotations. It returns one of the errors from CubieCube.Validate, or an
+// error if the orientation is impossible.
+func (o *OrientedCube) Validate() error {
+	if !o.Orientation.Valid() {
+		return errImpossibleCenters
+	}
+	return o.Cube.Validate()
+}
+
+// Normalize rotates the entire cube back to the standard orientation. It
+// returns the rotations which it applied.
+func (o *OrientedCube) Normalize() []Rotation {
+	rotations := o.Orientation.Rotations()
+	res := make([]Rotation, len(rotations))
+	for i, r := range rotations {
+		res[len(rotations)-(i+1)] = r.Inverse()
+	}
+	o.Orientation = StandardOrientation()
+	return res
+}
+
+// StickerCube returns the stickers of the cube as they appear in its current
+// orientation. Unlike o.Cube.StickerCube(), the centers may not be in their
+// standard positions.
+func (o *OrientedCube) StickerCube() StickerCube {
+	res := o.Cube.StickerCube()
+	for _, r := range o.Orientation.Rotations() {
+		res.Rotate(r)
+	}
+	return res
+}
+
+// OrientedCube converts stickers to an OrientedCube. Unlike CubieCube(), this
+// allows the centers to be in any orientation, so long as they use the
+// standard color scheme.
+func (s *StickerCube) OrientedCube() (*OrientedCube, error) {
+	var orientation Orientation
+	for i := range orientation {
+		orientation[i] = s[i*9+4]
+	}
+	if !orientation.Valid() {
+		return nil, errImpossibleCenters
+	}
+
+	normalized := *s
+	rotations := orientation.Rotations()
+	for i := len(rotations) - 1; i >= 0; i-- {
+		normalized.Rotate(rotations[i].Inverse())
+	}
+	cube, err := normalized.CubieCube()
+	if err != nil {
+		return nil, err
+	}
+	return &OrientedCube{*cube, orientation}, nil
+}
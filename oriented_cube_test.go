@@ -0,0 +1,67 @@
+package gocube
+
+import "testing"
+
+func TestOrientedCubeTurns(t *testing.T) {
+	cube := SolvedOrientedCube()
+	cube.Rotate(NewRotation(0, 1))
+	cube.Move(NewMove(1, 1))
+	expected := SolvedCubieCube()
+	expected.Move(NewMove(3, 1))
+	if cube.Cube != expected {
+		t.Error("U after x should turn F")
+	}
+
+	cube = SolvedOrientedCube()
+	cube.Turn(NewTurn(WideTurn, 5, 1))
+	expected = SolvedCubieCube()
+	expected.Move(NewMove(6, 1))
+	if cube.Cube != expected {
+		t.Error("Rw should turn L relative to the centers")
+	}
+	x := StandardOrientation()
+	x.Rotate(NewRotation(0, 1))
+	if cube.Orientation != x {
+		t.Error("Rw should rotate the centers like x")
+	}
+
+	if rotations := cube.Normalize(); len(rotations) != 1 ||
+		rotations[0] != NewRotation(0, -1) {
+		t.Errorf("unexpected normalization: %v", rotations)
+	}
+	if cube.Orientation != StandardOrientation() || cube.Cube != expected {
+		t.Error("normalization should only reset the orientation")
+	}
+}
+
+func TestOrientedCubeStickers(t *testing.T) {
+	algs := []string{"", "x", "y' z2", "R U M' Fw x' S2 y Dw'", "M2 E2 S2"}
+	for _, s := range algs {
+		alg, _ := ParseAlgorithm(s)
+		cube := SolvedOrientedCube()
+		cube.Apply(alg)
+
+		stickers := cube.StickerCube()
+		actual, err := stickers.OrientedCube()
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+		} else if *actual != cube {
+			t.Errorf("%q: stickers do not round trip", s)
+		}
+
+		normalized := cube
+		rotations := normalized.Normalize()
+		for _, r := range rotations {
+			stickers.Rotate(r)
+		}
+		if stickers != normalized.Cube.StickerCube() {
+			t.Errorf("%q: normalization does not match sticker rotations", s)
+		}
+	}
+
+	stickers := SolvedStickerCube()
+	stickers[4], stickers[9+4] = stickers[9+4], stickers[4]
+	if _, err := stickers.OrientedCube(); err == nil {
+		t.Error("expected error for swapped centers")
+	}
+}
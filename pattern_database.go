@@ -0,0 +1,798 @@
+package gocube
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/bits"
+)
+
+// patternUnknown marks entries of a pattern database which have not been
+// reached by the breadth-first search.
+const patternUnknown = 0xf
+
+// A nibbleArray packs 4-bit values, two per byte.
+type nibbleArray []byte
+
+func newNibbleArray(size int, value uint8) nibbleArray {
+	res := make(nibbleArray, (size+1)/2)
+	fill := value | value<<4
+	for i := range res {
+		res[i] = fill
+	}
+	return res
+}
+
+func (n nibbleArray) get(i int) uint8 {
+	if i&1 == 0 {
+		return n[i>>1] & 0xf
+	}
+	return n[i>>1] >> 4
+}
+
+func (n nibbleArray) set(i int, value uint8) {
+	if i&1 == 0 {
+		n[i>>1] = n[i>>1]&0xf0 | value
+	} else {
+		n[i>>1] = n[i>>1]&0xf | value<<4
+	}
+}
+
+// find returns the index of the first entry at or after i which has a value,
+// or size if there is none. It checks 16 entries at a time.
+func (n nibbleArray) find(i, size int, value uint8) int {
+	if i&1 != 0 && i < size {
+		if n.get(i) == value {
+			return i
+		}
+		i++
+	}
+	const ones = 0x1111111111111111
+	pattern := uint64(value) * ones
+	for ; i+16 <= size; i += 16 {
+		// This is only nonzero if one of the nibbles matches.
+		x := binary.LittleEndian.Uint64(n[i>>1:]) ^ pattern
+		if (x-ones)&^x&(ones<<3) != 0 {
+			break
+		}
+	}
+	for ; i < size; i++ {
+		if n.get(i) == value {
+			return i
+		}
+	}
+	return size
+}
+
+// A distanceTable stores a small distance for each state of a search space.
+type distanceTable interface {
+	get(i int) uint8
+	set(i int, value uint8)
+}
+
+// A findingTable is a distanceTable which can find entries with a given value
+// faster than checking each entry, which speeds up searches over tables where
+// few entries are at each depth.
+type findingTable interface {
+	distanceTable
+
+	// find returns the index of the first entry at or after i which has a
+	// value, or size if there is none.
+	find(i, size int, value uint8) int
+}
+
+// findEntry is like the find method of a findingTable, but it works for any
+// distanceTable.
+func findEntry(data distanceTable, i, size int, value uint8) int {
+	if table, ok := data.(findingTable); ok {
+		return table.find(i, size, value)
+	}
+	for ; i < size; i++ {
+		if data.get(i) == value {
+			return i
+		}
+	}
+	return size
+}
+
+// A byteTable is a distanceTable which uses a byte per entry.
+type byteTable []uint8
+
+func newByteTable(size int, value uint8) byteTable {
+	res := make(byteTable, size)
+	for i := range res {
+		res[i] = value
+	}
+	return res
+}
+
+func (b byteTable) get(i int) uint8 {
+	return b[i]
+}
+
+func (b byteTable) set(i int, value uint8) {
+	b[i] = value
+}
+
+func (b byteTable) find(i, size int, value uint8) int {
+	if i >= size {
+		return size
+	}
+	if j := bytes.IndexByte(b[i:size], value); j >= 0 {
+		return i + j
+	}
+	return size
+}
+
+// fillPatternDatabase performs a breadth-first search over the states of a
+// distance table, storing the depth of each state. Every entry should start
+// out as the same unknown value, like patternUnknown for a nibbleArray or 0xff
+// for a byteTable, and the depths must stay below it. A table may set other
+// entries along with the one it is asked to set, as long as they are at the
+// same depth.
+//
+// The neighbors function should fill a buffer with the indices of the states
+// reached by applying each move to a given state. The move set must be closed
+// under inverses.
+//
+// While the frontier is small, the search expands each state in the frontier.
+// Once the frontier is more than about a quarter of the unknown states, it is
+// faster to check each unknown state for a neighbor in the frontier instead,
+// since the check stops at the first neighbor it finds.
+//
+// If the table is a modularTable, the frontier is recognized by its distance
+// modulo the table's modulus. This works because the neighbors of a state
+// are at most one move closer or farther from the solved state.
+func fillPatternDatabase(data distanceTable, size, solved, moveCount int,
+	neighbors func(idx int, res []int)) {
+	unknown := data.get(solved)
+	data.set(solved, 0)
+	frontier := 1
+	remaining := size - 1
+
+	var modulus uint8
+	if table, ok := data.(modularTable); ok {
+		modulus = table.modulus()
+	}
+
+	adjacent := make([]int, moveCount)
+	for depth := uint8(0); frontier > 0 && remaining > 0; depth++ {
+		level := depth
+		if modulus != 0 {
+			level = depth % modulus
+		}
+		backward := frontier*4 > remaining
+		frontier = 0
+		for i := 0; i < size; i++ {
+			if backward {
+				if i = findEntry(data, i, size, unknown); i == size {
+					break
+				}
+				neighbors(i, adjacent)
+				for _, n := range adjacent {
+					if data.get(n) == level {
+						data.set(i, depth+1)
+						frontier++
+						break
+					}
+				}
+			} else {
+				if i = findEntry(data, i, size, level); i == size {
+					break
+				}
+				neighbors(i, adjacent)
+				for _, n := range adjacent {
+					if data.get(n) == unknown {
+						data.set(n, depth+1)
+						frontier++
+					}
+				}
+			}
+		}
+		remaining -= frontier
+		if frontier > 0 && modulus == 0 && depth+1 == unknown {
+			panic("pattern database is too deep")
+		}
+	}
+}
+
+// fillWeightedDistances is like fillPatternDatabase, but each move has a
+// positive cost. Entries should start out as unknown. Distances which would
+// reach unknown are stored as unknown-1, which is still a lower bound.
+//
+// The search finds the entries at each distance in turn, either by expanding
+// the entries which are one move away from it, or by checking each unknown
+// entry for such a neighbor. It makes a pass over the table for every
+// distance up to the largest one, so the costs should be small. Before each
+// pass, ctx is checked, and false is returned if it is done.
+func fillWeightedDistances(ctx context.Context, data distanceTable, size,
+	solved int, costs []uint8, unknown uint8,
+	neighbors func(idx int, res []int)) bool {
+	data.set(solved, 0)
+	counts := make([]int, unknown)
+	counts[0] = 1
+	remaining := size - 1
+
+	var maxCost uint8
+	for _, cost := range costs {
+		if cost > maxCost {
+			maxCost = cost
+		}
+	}
+
+	adjacent := make([]int, len(costs))
+	for depth := uint8(1); depth < unknown; depth++ {
+		// The entries at this depth are expanded from the ones at the
+		// depths one move away from it.
+		var expanding int
+		for d := int(depth) - int(maxCost); d < int(depth); d++ {
+			if d >= 0 {
+				expanding += counts[d]
+			}
+		}
+		if expanding == 0 {
+			break
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if depth == unknown-1 {
+			// Saturated entries may be found through each other, so the
+			// pass is repeated until it finds nothing new.
+			for found := true; found; {
+				if ctx.Err() != nil {
+					return false
+				}
+				found = false
+				for i := 0; i < size; i++ {
+					if i = findEntry(data, i, size, unknown); i == size {
+						break
+					}
+					neighbors(i, adjacent)
+					for _, n := range adjacent {
+						if data.get(n) != unknown {
+							data.set(i, depth)
+							found = true
+							break
+						}
+					}
+				}
+			}
+			break
+		}
+
+		if expanding*4 > remaining {
+			for i := 0; i < size; i++ {
+				if i = findEntry(data, i, size, unknown); i == size {
+					break
+				}
+				neighbors(i, adjacent)
+				for m, n := range adjacent {
+					if costs[m] <= depth && data.get(n) == depth-costs[m] {
+						data.set(i, depth)
+						counts[depth]++
+						break
+					}
+				}
+			}
+		} else {
+			for d := int(depth) - int(maxCost); d < int(depth); d++ {
+				if d < 0 || counts[d] == 0 {
+					continue
+				}
+				for i := 0; i < size; i++ {
+					if i = findEntry(data, i, size, uint8(d)); i == size {
+						break
+					}
+					neighbors(i, adjacent)
+					for m, n := range adjacent {
+						if d+int(costs[m]) == int(depth) &&
+							data.get(n) == unknown {
+							data.set(n, depth)
+							counts[depth]++
+						}
+					}
+				}
+			}
+		}
+		remaining -= counts[depth]
+	}
+	return true
+}
+
+// CornerPatternDatabase stores the number of moves needed to solve every
+// state of the corners.
+//
+// It is a PatternDatabase which tracks the slot and twist of every corner, so
+// the table has 8! * 3^7 = 88179840 entries, stored in 4 bits each.
+type CornerPatternDatabase struct {
+	db *PatternDatabase
+}
+
+// NewCornerPatternDatabase generates a CornerPatternDatabase.
+//
+// This takes a while and uses about 60MB of memory.
+func NewCornerPatternDatabase() *CornerPatternDatabase {
+	// Unlike NewPatternDatabase, this keeps the move tables, which the
+	// OptimalSolver uses to move between entries.
+	return &CornerPatternDatabase{
+		db: newPatternDatabase(PieceSet{
+			Corners:      []int{0, 1, 2, 3, 4, 5, 6, 7},
+			CornerTwists: true,
+		}, NibbleStorage),
+	}
+}
+
+// Lookup returns the number of moves needed to solve a set of corners.
+func (c *CornerPatternDatabase) Lookup(corners *CubieCorners) int {
+	return c.db.distance(c.db.cornerIndex(corners))
+}
+
+// EdgePatternDatabase stores the number of moves needed to solve a subset of
+// the edges, ignoring the rest of the cube.
+//
+// A state is given by the slot and flip of each tracked piece, so the table
+// has 12!/(12-n)! * 2^n entries for n pieces, stored in 4 bits each. Six
+// pieces require about 21MB, and about 117MB while they are generated, since
+// the move tables of a PatternDatabase are needed as well.
+type EdgePatternDatabase struct {
+	// Pieces lists the tracked edge pieces.
+	Pieces []int
+
+	data nibbleArray
+}
+
+// NewEdgePatternDatabase generates an EdgePatternDatabase for a set of edge
+// pieces.
+func NewEdgePatternDatabase(pieces []int) *EdgePatternDatabase {
+	db := NewPatternDatabase(PieceSet{Edges: pieces, EdgeFlips: true},
+		NibbleStorage)
+	return &EdgePatternDatabase{
+		Pieces: db.Pieces.Edges,
+		data:   db.data.(nibbleArray),
+	}
+}
+
+// Lookup returns the number of moves needed to solve the tracked edges.
+func (e *EdgePatternDatabase) Lookup(edges *CubieEdges) int {
+	var positions [12]int8
+	var flips uint16
+	for slot, edge := range edges {
+		positions[edge.Piece] = int8(slot)
+		if edge.Flip {
+			flips |= 1 << uint(edge.Piece)
+		}
+	}
+	return e.lookupPositions(&positions, flips)
+}
+
+// lookupPositions is like Lookup, but it takes the slot of every piece and a
+// bitmask of the flipped pieces.
+func (e *EdgePatternDatabase) lookupPositions(positions *[12]int8,
+	flips uint16) int {
+	// The entries are laid out like those of a PatternDatabase, which leaves
+	// out the last flip when every edge is tracked.
+	flipCount := len(e.Pieces)
+	if flipCount == 12 {
+		flipCount--
+	}
+	var used uint16
+	var idx int
+	var flipBits int
+	for i, piece := range e.Pieces {
+		slot := uint(positions[piece])
+		idx = idx*(12-i) + int(slot) -
+			bits.OnesCount16(used&(1<<slot-1))
+		used |= 1 << slot
+		if i < flipCount && flips&(1<<uint(piece)) != 0 {
+			flipBits |= 1 << uint(i)
+		}
+	}
+	return int(e.data.get(idx<<uint(flipCount) | flipBits))
+}
+
+// edgeSlotMoves maps each edge slot to the slot it moves to under each Move.
+// edgeFlipMoves indicates whether the piece in the slot is flipped by the move.
+var edgeSlotMoves, edgeFlipMoves = edgeMoveTables()
+
+func edgeMoveTables() (slots [18][12]int8, flips [18][12]bool) {
+	for m := 0; m < 18; m++ {
+		edges := SolvedCubieEdges()
+		edges.Move(Move(m))
+		for slot, edge := range edges {
+			slots[m][edge.Piece] = int8(slot)
+			flips[m][edge.Piece] = edge.Flip
+		}
+	}
+	return
+}
+
+// cornerSlotMoves maps each corner slot to the slot it moves to under each
+// Move. cornerTwistMoves gives the twist which the move adds to the piece in
+// the slot.
+var cornerSlotMoves, cornerTwistMoves = cornerMoveTables()
+
+func cornerMoveTables() (slots, twists [18][8]int8) {
+	for m := 0; m < 18; m++ {
+		corners := SolvedCubieCorners()
+		corners.Move(Move(m))
+		for slot, corner := range corners {
+			slots[m][corner.Piece] = int8(slot)
+			twists[m][corner.Piece] = int8(corners.Twist(slot))
+		}
+	}
+	return
+}
+
+// edgePositionCount returns the number of ways to place n distinct edges.
+func edgePositionCount(n int) int {
+	return partialPermutationCount(12, n)
+}
+
+// edgePositionIndex encodes an ordered list of distinct edge slots.
+func edgePositionIndex(slots []int) int {
+	return encodePartialPermutation(slots, 12)
+}
+
+// edgePositionDecode is the inverse of edgePositionIndex.
+func edgePositionDecode(idx int, slots []int) {
+	decodePartialPermutation(idx, 12, slots)
+}
+
+// A mod3Array packs distances modulo 3 into 2 bits each, four per byte. The
+// fourth value marks unknown entries.
+type mod3Array []byte
+
+func newMod3Array(size int) mod3Array {
+	res := make(mod3Array, (size+3)/4)
+	for i := range res {
+		res[i] = 0xff
+	}
+	return res
+}
+
+// get returns a distance modulo 3, or patternUnknown.
+func (m mod3Array) get(i int) uint8 {
+	res := m[i>>2] >> uint((i&3)*2) & 3
+	if res == 3 {
+		return patternUnknown
+	}
+	return res
+}
+
+// set stores a distance modulo 3.
+func (m mod3Array) set(i int, value uint8) {
+	shift := uint((i & 3) * 2)
+	m[i>>2] = m[i>>2]&^(3<<shift) | value%3<<shift
+}
+
+func (m mod3Array) modulus() uint8 {
+	return 3
+}
+
+// A modularTable is a distanceTable which only stores distances modulo some
+// number. Unknown entries are still reported as patternUnknown.
+type modularTable interface {
+	distanceTable
+	modulus() uint8
+}
+
+// PatternStorage determines how a PatternDatabase stores its entries.
+type PatternStorage int
+
+const (
+	// NibbleStorage stores each distance in 4 bits, so it can store
+	// distances up to 14 moves.
+	NibbleStorage PatternStorage = iota
+
+	// Mod3Storage stores each distance modulo 3 in 2 bits, which halves the
+	// size of the database. A lookup recovers the distance by walking towards
+	// the solved state, which takes up to 18 table reads for each move of the
+	// distance.
+	Mod3Storage
+)
+
+// A PieceSet selects the pieces of a cube which a PatternDatabase tracks.
+type PieceSet struct {
+	// Corners and Edges list the tracked pieces, such as CornerUFR or
+	// EdgeUF.
+	Corners []int
+	Edges   []int
+
+	// CornerTwists and EdgeFlips indicate whether the orientations of the
+	// tracked pieces are tracked as well as their positions.
+	CornerTwists bool
+	EdgeFlips    bool
+}
+
+// A PatternDatabase stores the number of moves needed to solve the tracked
+// pieces of a cube, for every state of those pieces.
+//
+// A state is given by the slot of each tracked piece, and by its orientation
+// if orientations are tracked. With n corners and m edges, there are
+// 8!/(8-n)! * 12!/(12-m)! positions, which is multiplied by 3^n and 2^m for
+// the orientations. When every corner or every edge is tracked, the last
+// orientation is implied by the others, so it is left out.
+//
+// While a database is generated, it also uses move tables with 144 bytes for
+// each position of the tracked corners and for each position of the tracked
+// edges. For six edges, this is about 96MB, and for seven edges it is about
+// 575MB, which is far larger than the database itself. Unless the database
+// uses Mod3Storage, which needs the tables for lookups, they are freed once
+// the database is generated.
+type PatternDatabase struct {
+	Pieces  PieceSet
+	Storage PatternStorage
+
+	corners *pieceCoordinate
+	edges   *pieceCoordinate
+	data    distanceTable
+	size    int
+	solved  int
+}
+
+// NewPatternDatabase generates a PatternDatabase with a breadth-first search.
+func NewPatternDatabase(pieces PieceSet,
+	storage PatternStorage) *PatternDatabase {
+	res := newPatternDatabase(pieces, storage)
+	if storage != Mod3Storage {
+		// Only a Mod3Storage lookup moves between entries, and the move
+		// tables can be much larger than the database.
+		res.corners.releaseMoves()
+		res.edges.releaseMoves()
+	}
+	return res
+}
+
+func newPatternDatabase(pieces PieceSet,
+	storage PatternStorage) *PatternDatabase {
+	res := &PatternDatabase{
+		Pieces: PieceSet{
+			Corners:      append([]int{}, pieces.Corners...),
+			Edges:        append([]int{}, pieces.Edges...),
+			CornerTwists: pieces.CornerTwists,
+			EdgeFlips:    pieces.EdgeFlips,
+		},
+		Storage: storage,
+	}
+	cornerTwists := 1
+	if pieces.CornerTwists {
+		cornerTwists = 3
+	}
+	edgeFlips := 1
+	if pieces.EdgeFlips {
+		edgeFlips = 2
+	}
+	res.corners = newPieceCoordinate(pieces.Corners, 8, cornerTwists,
+		func(m, slot int) (int, int) {
+			return int(cornerSlotMoves[m][slot]), int(cornerTwistMoves[m][slot])
+		})
+	res.edges = newPieceCoordinate(pieces.Edges, 12, edgeFlips,
+		func(m, slot int) (int, int) {
+			if edgeFlipMoves[m][slot] {
+				return int(edgeSlotMoves[m][slot]), 1
+			}
+			return int(edgeSlotMoves[m][slot]), 0
+		})
+
+	res.size = res.corners.size * res.edges.size
+	res.solved = res.corners.solved*res.edges.size + res.edges.solved
+	if storage == Mod3Storage {
+		res.data = newMod3Array(res.size)
+	} else {
+		res.data = newNibbleArray(res.size, patternUnknown)
+	}
+	fillPatternDatabase(res.data, res.size, res.solved, 18, res.neighbors)
+	return res
+}
+
+// Size returns the number of entries in the database.
+func (p *PatternDatabase) Size() int {
+	return p.size
+}
+
+// Lookup returns the number of moves needed to solve the tracked pieces of a
+// cube. It is a lower bound on the number of moves to solve the cube.
+func (p *PatternDatabase) Lookup(c *CubieCube) int {
+	var edgeSlots [12]int
+	var edgeFlips [12]int
+	for slot, edge := range c.Edges {
+		edgeSlots[edge.Piece] = slot
+		if edge.Flip {
+			edgeFlips[edge.Piece] = 1
+		}
+	}
+	idx := p.cornerIndex(&c.Corners)*p.edges.size +
+		p.edges.index(edgeSlots[:], edgeFlips[:])
+	return p.distance(idx)
+}
+
+// cornerIndex encodes the tracked corners of a cube.
+func (p *PatternDatabase) cornerIndex(corners *CubieCorners) int {
+	var slots [8]int
+	var twists [8]int
+	for slot, corner := range corners {
+		slots[corner.Piece] = slot
+		twists[corner.Piece] = corners.Twist(slot)
+	}
+	return p.corners.index(slots[:], twists[:])
+}
+
+// distance reads an entry of the database. With Mod3Storage, the distance is
+// found by moving to a neighbor which is one move closer to the solved state
+// until the solved state is reached.
+//
+// It panics if the walk reaches an entry which has no neighbor closer to the
+// solved state, which can only happen if the table is damaged.
+func (p *PatternDatabase) distance(idx int) int {
+	if p.Storage != Mod3Storage {
+		return int(p.data.get(idx))
+	}
+	var res int
+	var neighbors [18]int
+	for idx != p.solved {
+		value := p.data.get(idx)
+		if value == patternUnknown {
+			panic("gocube: pattern database has an unknown entry")
+		}
+		closer := (value + 2) % 3
+		p.neighbors(idx, neighbors[:])
+		next := -1
+		for _, n := range neighbors {
+			if p.data.get(n) == closer {
+				next = n
+				break
+			}
+		}
+		if next < 0 {
+			panic("gocube: pattern database entry has no neighbor closer " +
+				"to the solved state")
+		}
+		idx = next
+		res++
+	}
+	return res
+}
+
+func (p *PatternDatabase) neighbors(idx int, res []int) {
+	corners, edges := idx/p.edges.size, idx%p.edges.size
+	for m := range res {
+		res[m] = p.corners.move(corners, m)*p.edges.size +
+			p.edges.move(edges, m)
+	}
+}
+
+// A pieceCoordinate indexes the slots and orientations of a list of corner
+// pieces or edge pieces.
+//
+// An index is a position index, given by encodePartialPermutation, followed
+// by a digit for the orientation of each piece. The orientation of the last
+// piece is left out when every piece is tracked.
+type pieceCoordinate struct {
+	pieces       []int
+	slotCount    int
+	orientations int
+
+	// digitPowers stores the place value of each orientation digit.
+	digitPowers []int
+
+	positions  int
+	orientSize int
+	size       int
+	solved     int
+
+	// moves stores the new position index for each position and move.
+	// changes stores the orientation digits which each move adds to the
+	// pieces in a position, modulo the number of orientations.
+	moves   []int32
+	changes []int32
+
+	// twistSums adds two sets of orientation digits modulo 3. It is nil if
+	// there are two orientations, since XOR adds them.
+	twistSums []uint16
+}
+
+// newPieceCoordinate creates a pieceCoordinate. The move function gives the
+// slot which a move sends the piece in a slot to, and the orientation which
+// it adds to the piece.
+func newPieceCoordinate(pieces []int, slotCount, orientations int,
+	move func(m, slot int) (int, int)) *pieceCoordinate {
+	res := &pieceCoordinate{
+		pieces:       pieces,
+		slotCount:    slotCount,
+		orientations: orientations,
+		positions:    partialPermutationCount(slotCount, len(pieces)),
+		orientSize:   1,
+	}
+	if orientations > 1 {
+		digits := len(pieces)
+		if digits == slotCount {
+			digits--
+		}
+		for i := 0; i < digits; i++ {
+			res.digitPowers = append(res.digitPowers, res.orientSize)
+			res.orientSize *= orientations
+		}
+	}
+	res.size = res.positions * res.orientSize
+	if orientations == 3 {
+		res.twistSums = make([]uint16, res.orientSize*res.orientSize)
+		for i := 0; i < res.orientSize; i++ {
+			for j := 0; j < res.orientSize; j++ {
+				var sum int
+				for _, power := range res.digitPowers {
+					sum += (i/power%3 + j/power%3) % 3 * power
+				}
+				res.twistSums[i*res.orientSize+j] = uint16(sum)
+			}
+		}
+	}
+
+	solvedSlots := make([]int, slotCount)
+	for i := range solvedSlots {
+		solvedSlots[i] = i
+	}
+	res.solved = res.index(solvedSlots, make([]int, slotCount))
+
+	res.moves = make([]int32, res.positions*18)
+	res.changes = make([]int32, res.positions*18)
+	slots := make([]int, len(pieces))
+	newSlots := make([]int, len(pieces))
+	for i := 0; i < res.positions; i++ {
+		decodePartialPermutation(i, slotCount, slots)
+		for m := 0; m < 18; m++ {
+			var change int
+			for j, slot := range slots {
+				var orientation int
+				newSlots[j], orientation = move(m, slot)
+				if j < len(res.digitPowers) {
+					change += orientation * res.digitPowers[j]
+				}
+			}
+			res.moves[i*18+m] = int32(encodePartialPermutation(newSlots,
+				slotCount))
+			res.changes[i*18+m] = int32(change)
+		}
+	}
+	return res
+}
+
+// index encodes a state, given the slot and orientation of every piece,
+// indexed by piece.
+func (p *pieceCoordinate) index(slots, orientations []int) int {
+	var used uint64
+	var position int
+	var orientation int
+	for i, piece := range p.pieces {
+		slot := slots[piece]
+		position = position*(p.slotCount-i) + slot -
+			bits.OnesCount64(used&(1<<uint(slot)-1))
+		used |= 1 << uint(slot)
+		if i < len(p.digitPowers) {
+			orientation += orientations[piece] * p.digitPowers[i]
+		}
+	}
+	return position*p.orientSize + orientation
+}
+
+// releaseMoves frees the tables used by move, which may not be called
+// afterwards.
+func (p *pieceCoordinate) releaseMoves() {
+	p.moves = nil
+	p.changes = nil
+	p.twistSums = nil
+}
+
+// move applies a move to an index.
+func (p *pieceCoordinate) move(idx, m int) int {
+	position, orientation := idx/p.orientSize, idx%p.orientSize
+	entry := position*18 + m
+	change := int(p.changes[entry])
+	if p.twistSums != nil {
+		orientation = int(p.twistSums[change*p.orientSize+orientation])
+	} else {
+		orientation ^= change
+	}
+	return int(p.moves[entry])*p.orientSize + orientation
+}
@@ -0,0 +1,206 @@
+package gocube
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNibbleArray(t *testing.T) {
+	arr := newNibbleArray(5, 3)
+	arr.set(1, 9)
+	arr.set(4, 15)
+	expected := []uint8{3, 9, 3, 3, 15}
+	for i, x := range expected {
+		if actual := arr.get(i); actual != x {
+			t.Errorf("entry %d: expected %d but got %d", i, x, actual)
+		}
+	}
+}
+
+func TestMod3Array(t *testing.T) {
+	arr := newMod3Array(6)
+	arr.set(1, 4)
+	arr.set(4, 2)
+	arr.set(5, 3)
+	expected := []uint8{patternUnknown, 1, patternUnknown, patternUnknown, 2, 0}
+	for i, x := range expected {
+		if actual := arr.get(i); actual != x {
+			t.Errorf("entry %d: expected %d but got %d", i, x, actual)
+		}
+	}
+}
+
+func TestEdgePositionIndex(t *testing.T) {
+	slots := make([]int, 4)
+	for i := 0; i < edgePositionCount(4); i++ {
+		edgePositionDecode(i, slots)
+		if actual := edgePositionIndex(slots); actual != i {
+			t.Fatalf("index %d decoded to %v, which encodes to %d", i, slots,
+				actual)
+		}
+	}
+}
+
+func TestEdgePatternDatabase(t *testing.T) {
+	db := NewEdgePatternDatabase([]int{EdgeUF, EdgeUR, EdgeFR})
+	var counts [16]int
+	for i := 0; i < edgePositionCount(3)<<3; i++ {
+		counts[db.data.get(i)]++
+	}
+	if counts[0] != 1 || counts[patternUnknown] != 0 {
+		t.Errorf("unexpected depth counts %v", counts)
+	}
+
+	for i := 0; i < 100; i++ {
+		edges := SolvedCubieEdges()
+		length := rand.Intn(8)
+		for j := 0; j < length; j++ {
+			edges.Move(Move(rand.Intn(18)))
+		}
+		if bound := db.Lookup(&edges); bound > length {
+			t.Errorf("bound %d exceeds scramble length %d", bound, length)
+		}
+	}
+
+	edges := SolvedCubieEdges()
+	edges.Move(NewMove(3, 1))
+	if bound := db.Lookup(&edges); bound != 1 {
+		t.Errorf("expected bound 1 for F but got %d", bound)
+	}
+	edges.Move(NewMove(3, -1))
+	edges.Move(NewMove(4, 2))
+	if bound := db.Lookup(&edges); bound != 0 {
+		t.Errorf("expected bound 0 for B2 but got %d", bound)
+	}
+}
+
+func TestCornerPatternDatabase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generating the corner database is slow")
+	}
+	db := NewCornerPatternDatabase()
+
+	// These counts were published by Korf in 1997.
+	expected := []int{1, 18, 243, 2874, 28000, 205416, 1168516, 5402628,
+		20776176, 45391616, 15139616, 64736}
+	var counts [16]int
+	if size := db.db.Size(); size != 88179840 {
+		t.Fatalf("unexpected size %d", size)
+	}
+	for i := 0; i < db.db.Size(); i++ {
+		counts[db.db.data.get(i)]++
+	}
+	for depth, count := range expected {
+		if counts[depth] != count {
+			t.Errorf("depth %d: expected %d states but got %d", depth, count,
+				counts[depth])
+		}
+	}
+
+	corners := SolvedCubieCorners()
+	corners.Move(NewMove(5, 1))
+	corners.Move(NewMove(1, 1))
+	if bound := db.Lookup(&corners); bound != 2 {
+		t.Errorf("expected bound 2 for R U but got %d", bound)
+	}
+
+	// An optimalNode moves between the entries of the database.
+	cube := SolvedCubieCube()
+	node := newOptimalNode(&cube, db)
+	for i := 0; i < 30; i++ {
+		m := Move(rand.Intn(18))
+		cube.Move(m)
+		node.move(m, db)
+		expected := db.db.cornerIndex(&cube.Corners)
+		if node.corners != expected {
+			t.Fatalf("move %d: expected index %d but got %d", i, expected,
+				node.corners)
+		}
+	}
+}
+
+func TestPatternDatabase(t *testing.T) {
+	pieces := PieceSet{
+		Corners:      []int{CornerUFR, CornerDLB},
+		Edges:        []int{EdgeUF, EdgeDR, EdgeBL},
+		CornerTwists: true,
+		EdgeFlips:    true,
+	}
+	nibbles := NewPatternDatabase(pieces, NibbleStorage)
+	mod3 := NewPatternDatabase(pieces, Mod3Storage)
+	if size := nibbles.Size(); size != 56*9*1320*8 {
+		t.Fatalf("unexpected size %d", size)
+	}
+
+	for i := 0; i < 100; i++ {
+		cube := SolvedCubieCube()
+		length := rand.Intn(10)
+		for j := 0; j < length; j++ {
+			cube.Move(Move(rand.Intn(18)))
+		}
+		bound := nibbles.Lookup(&cube)
+		if bound > length {
+			t.Errorf("bound %d exceeds scramble length %d", bound, length)
+		}
+		if actual := mod3.Lookup(&cube); actual != bound {
+			t.Errorf("mod 3 lookup gave %d instead of %d", actual, bound)
+		}
+	}
+
+	cube := SolvedCubieCube()
+	if bound := mod3.Lookup(&cube); bound != 0 {
+		t.Errorf("expected bound 0 for solved cube but got %d", bound)
+	}
+	moves, _ := ParseMoves("R U")
+	for _, m := range moves {
+		cube.Move(m)
+	}
+	if bound := mod3.Lookup(&cube); bound != 2 {
+		t.Errorf("expected bound 2 for R U but got %d", bound)
+	}
+}
+
+func TestPatternDatabaseDamaged(t *testing.T) {
+	db := NewPatternDatabase(PieceSet{Edges: []int{EdgeUF, EdgeUR}},
+		Mod3Storage)
+	cube := SolvedCubieCube()
+	cube.Move(NewMove(1, 1))
+
+	expectPanic := func(name string) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected a panic", name)
+			}
+		}()
+		db.Lookup(&cube)
+	}
+
+	data := db.data.(mod3Array)
+	for i := 0; i < db.Size(); i++ {
+		if i != db.solved {
+			data.set(i, 2)
+		}
+	}
+	expectPanic("no closer entry")
+
+	for i := range data {
+		data[i] = 0xff
+	}
+	data.set(db.solved, 0)
+	expectPanic("unknown entry")
+}
+
+func TestPatternDatabaseMatchesEdges(t *testing.T) {
+	pieces := []int{EdgeUF, EdgeUR, EdgeFR}
+	db := NewPatternDatabase(PieceSet{Edges: pieces}, NibbleStorage)
+	flips := NewEdgePatternDatabase(pieces)
+	for i := 0; i < 100; i++ {
+		cube := SolvedCubieCube()
+		for j := 0; j < 8; j++ {
+			cube.Move(Move(rand.Intn(18)))
+		}
+		if bound := db.Lookup(&cube); bound > flips.Lookup(&cube.Edges) {
+			t.Errorf("ignoring flips gave a larger bound %d", bound)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package gocube
 
+import "math/bits"
+
 var factorials = []int{1, 1, 2, 6, 24, 120, 720, 5040, 40320, 362880, 3628800,
 	39916800, 479001600}
 
@@ -164,3 +166,60 @@ func factorial(n int) int {
 	}
 	return factorials[n]
 }
+
+// decodePermutation is the inverse of encodePermutation.
+func decodePermutation(index, size int) []int {
+	remaining := make([]int, size)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	res := make([]int, size)
+	for i := 0; i < size; i++ {
+		f := factorial(size - 1 - i)
+		digit := index / f
+		index %= f
+		res[i] = remaining[digit]
+		remaining = append(remaining[:digit], remaining[digit+1:]...)
+	}
+	return res
+}
+
+// partialPermutationCount returns the number of ways to place n distinct
+// pieces in size slots.
+func partialPermutationCount(size, n int) int {
+	return factorial(size) / factorial(size-n)
+}
+
+// encodePartialPermutation encodes an ordered list of distinct slots, each of
+// which is less than size.
+func encodePartialPermutation(slots []int, size int) int {
+	var used uint64
+	var res int
+	for i, slot := range slots {
+		res = res*(size-i) + slot - bits.OnesCount64(used&(1<<uint(slot)-1))
+		used |= 1 << uint(slot)
+	}
+	return res
+}
+
+// decodePartialPermutation is the inverse of encodePartialPermutation.
+func decodePartialPermutation(idx, size int, slots []int) {
+	for i := len(slots) - 1; i >= 0; i-- {
+		slots[i] = idx % (size - i)
+		idx /= size - i
+	}
+	var used uint64
+	for i, rank := range slots {
+		for slot := 0; slot < size; slot++ {
+			if used&(1<<uint(slot)) != 0 {
+				continue
+			}
+			if rank == 0 {
+				slots[i] = slot
+				used |= 1 << uint(slot)
+				break
+			}
+			rank--
+		}
+	}
+}
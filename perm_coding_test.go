@@ -92,3 +92,38 @@ func permutationParity(seqIn []int) bool {
 	}
 	return result
 }
+
+func TestDecodePermutation(t *testing.T) {
+	for length := 0; length < 8; length++ {
+		testSet := allPermutations(length)
+		for j, perm := range testSet {
+			decoded := decodePermutation(j, length)
+			for k, x := range perm {
+				if decoded[k] != x {
+					t.Error("Decoded", j, "expected", perm, "but got", decoded)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestPartialPermutation(t *testing.T) {
+	for _, size := range []int{6, 8, 12} {
+		count := partialPermutationCount(size, 4)
+		slots := make([]int, 4)
+		for i := 0; i < count; i++ {
+			decodePartialPermutation(i, size, slots)
+			used := map[int]bool{}
+			for _, slot := range slots {
+				if slot < 0 || slot >= size || used[slot] {
+					t.Fatalf("size %d: invalid slots %v for %d", size, slots, i)
+				}
+				used[slot] = true
+			}
+			if idx := encodePartialPermutation(slots, size); idx != i {
+				t.Fatalf("size %d: expected %d but got %d", size, i, idx)
+			}
+		}
+	}
+}
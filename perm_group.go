@@ -0,0 +1,179 @@
+package gocube
+
+import "math/big"
+
+// A stickerPerm describes how a cube's stickers move. Each entry is indexed by
+// a sticker position, as in StickerCube, and stores the position which the
+// sticker in that position moves to.
+type stickerPerm [54]uint8
+
+func identityStickerPerm() stickerPerm {
+	var res stickerPerm
+	for i := range res {
+		res[i] = uint8(i)
+	}
+	return res
+}
+
+// then returns the permutation which applies p and then q.
+func (p *stickerPerm) then(q *stickerPerm) stickerPerm {
+	var res stickerPerm
+	for i, x := range p {
+		res[i] = q[x]
+	}
+	return res
+}
+
+func (p *stickerPerm) inverse() stickerPerm {
+	var res stickerPerm
+	for i, x := range p {
+		res[x] = uint8(i)
+	}
+	return res
+}
+
+func (p *stickerPerm) isIdentity() bool {
+	for i, x := range p {
+		if int(x) != i {
+			return false
+		}
+	}
+	return true
+}
+
+// A permGroup is a group of sticker permutations, stored as a stabilizer chain
+// which is computed with the Schreier-Sims algorithm.
+//
+// Each level of the chain stabilizes the base points of the levels before it,
+// so an element of the group can be written as a product of one transversal
+// element from each level.
+type permGroup struct {
+	levels []*permGroupLevel
+}
+
+type permGroupLevel struct {
+	point int
+
+	// gens are the strong generators which fix the points of the previous
+	// levels but not this level's point.
+	gens []stickerPerm
+
+	// transversal stores, for each point in the orbit of this level's point,
+	// an element of the level's group which moves this level's point there.
+	transversal [54]*stickerPerm
+	orbit       []int
+}
+
+// newPermGroup computes a stabilizer chain for the group generated by a list
+// of permutations.
+func newPermGroup(gens []stickerPerm) *permGroup {
+	res := &permGroup{}
+	for _, g := range gens {
+		if !g.isIdentity() {
+			res.addGenerator(0, g)
+		}
+	}
+
+	// Every Schreier generator of a level must be a member of the group of
+	// the next level. When one isn't, it is added to the chain, and the
+	// levels above it are checked again.
+	for i := len(res.levels) - 1; i >= 0; {
+		gens := res.levelGenerators(i)
+		level := res.levels[i]
+		level.computeOrbit(gens)
+		added := -1
+	SchreierLoop:
+		for _, x := range level.orbit {
+			for j := range gens {
+				image := gens[j][x]
+				h := level.transversal[x].then(&gens[j])
+				inv := level.transversal[image].inverse()
+				h = h.then(&inv)
+				residue, failLevel := res.sift(h, i+1)
+				if !residue.isIdentity() {
+					res.addGenerator(failLevel, residue)
+					added = failLevel
+					break SchreierLoop
+				}
+			}
+		}
+		if added >= 0 {
+			i = added
+		} else {
+			i--
+		}
+	}
+	return res
+}
+
+// contains checks if a permutation is in the group.
+func (p *permGroup) contains(g stickerPerm) bool {
+	residue, _ := p.sift(g, 0)
+	return residue.isIdentity()
+}
+
+// order returns the number of elements in the group.
+func (p *permGroup) order() *big.Int {
+	res := big.NewInt(1)
+	for _, level := range p.levels {
+		res.Mul(res, big.NewInt(int64(len(level.orbit))))
+	}
+	return res
+}
+
+// sift divides a permutation by transversal elements, starting at a given
+// level, until it fixes every base point or it cannot be divided further. It
+// returns the remaining permutation and the level where sifting stopped.
+func (p *permGroup) sift(g stickerPerm, start int) (stickerPerm, int) {
+	for i := start; i < len(p.levels); i++ {
+		level := p.levels[i]
+		t := level.transversal[g[level.point]]
+		if t == nil {
+			return g, i
+		}
+		inv := t.inverse()
+		g = g.then(&inv)
+	}
+	return g, len(p.levels)
+}
+
+// addGenerator adds a strong generator to a level, creating a new level if
+// the generator fixes every base point.
+func (p *permGroup) addGenerator(level int, g stickerPerm) {
+	if level == len(p.levels) {
+		point := 0
+		for int(g[point]) == point {
+			point++
+		}
+		p.levels = append(p.levels, &permGroupLevel{point: point})
+	}
+	p.levels[level].gens = append(p.levels[level].gens, g)
+}
+
+// levelGenerators returns the generators of the group at a level, which
+// include the generators of every later level.
+func (p *permGroup) levelGenerators(level int) []stickerPerm {
+	var res []stickerPerm
+	for _, l := range p.levels[level:] {
+		res = append(res, l.gens...)
+	}
+	return res
+}
+
+func (l *permGroupLevel) computeOrbit(gens []stickerPerm) {
+	l.transversal = [54]*stickerPerm{}
+	identity := identityStickerPerm()
+	l.transversal[l.point] = &identity
+	l.orbit = []int{l.point}
+	for i := 0; i < len(l.orbit); i++ {
+		x := l.orbit[i]
+		for j := range gens {
+			image := int(gens[j][x])
+			if l.transversal[image] == nil {
+				t := l.transversal[x].then(&gens[j])
+				l.transversal[image] = &t
+				l.orbit = append(l.orbit, image)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package gocube
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPermGroupOrder(t *testing.T) {
+	orders := map[string]string{
+		"R U":         "73483200",
+		"R2 U2":       "12",
+		"U D F B R L": "43252003274489856000",
+	}
+	for gens, expected := range orders {
+		alg, err := ParseAlgorithm(gens)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var perms []stickerPerm
+		for _, g := range alg {
+			perms = append(perms, turnStickerPerm(g))
+		}
+		actual := newPermGroup(perms).order()
+		if expectedInt, _ := new(big.Int).SetString(expected, 10); actual.Cmp(expectedInt) != 0 {
+			t.Errorf("<%s>: expected order %s but got %s", gens, expected, actual)
+		}
+	}
+}
+
+func TestPermGroupContains(t *testing.T) {
+	r := turnStickerPerm(MoveTurn(NewMove(5, 1)))
+	u := turnStickerPerm(MoveTurn(NewMove(1, 1)))
+	f := turnStickerPerm(MoveTurn(NewMove(3, 1)))
+	group := newPermGroup([]stickerPerm{r, u})
+
+	sexy := r.then(&u)
+	rInv := r.inverse()
+	uInv := u.inverse()
+	sexy = sexy.then(&rInv)
+	sexy = sexy.then(&uInv)
+	if !group.contains(sexy) {
+		t.Error("R U R' U' should be in <R,U>")
+	}
+	if group.contains(f) {
+		t.Error("F should not be in <R,U>")
+	}
+	if !group.contains(identityStickerPerm()) {
+		t.Error("identity should be in <R,U>")
+	}
+}
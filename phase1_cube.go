@@ -1,35 +1,40 @@
 package gocube
 
+// xSymmetry translates moves from the Y axis phase-1 cube to moves on the X
+// axis cube. The mapping is: F->F, B->B, U->R, D->L, L->U, R->D.
+// For example, doing U on a Y-axis cube is like doing R on the X-axis version
+// of that cube.
+// This mapping is kind of like doing a "z'" rotation before the move.
+var xSymmetry = RotationSymmetry(NewRotation(2, -1))
+
 // xCornerIndices are the indexes of the corners on the Y axis cube which
 // correspond to the corners on the X axis cube. An index in this array
 // corresponds to the physical slot in the X axis cube. A value in this array
 // corresponds to the physical slot in the Y axis cube.
-var xCornerIndices []int = []int{1, 3, 0, 2, 5, 7, 4, 6}
+var xCornerIndices []int = xSymmetry.Inverse().cornerSlots()
 
 // xEdgeIndices are the indexes of the edges on the Y axis cube which correspond
 // to edges on the X axis cube. An index in this array corresponds to the
 // physical slot in the X axis cube. A value in this array corresponds to the
 // physical slot in the Y axis cube.
-var xEdgeIndices []int = []int{3, 0, 1, 2, 10, 4, 9, 6, 7, 8, 11, 5}
+var xEdgeIndices []int = xSymmetry.Inverse().edgeSlots()
 
 // xMoveTranslation maps moves from the Y axis phase-1 cube to moves on the X
-// axis cube. The mapping is: F->F, B->B, U->R, D->L, L->U, R->D.
-// For example, doing U on a Y-axis cube is like doing R on the X-axis version
-// of that cube.
-// This mapping is kind of like doing a "z" rotation before the move.
-var xMoveTranslation []Move = []Move{4, 5, 2, 3, 1, 0, 10, 11, 8, 9, 7, 6, 16,
-	17, 14, 15, 13, 12}
+// axis cube, as described by xSymmetry.
+var xMoveTranslation []Move = xSymmetry.moveTable()
+
+// zSymmetry is like xSymmetry, but it's for doing an "x'" rotation before
+// applying a move. The mapping is: R->R, L->L, F->U, B->D, U->B, D->F.
+var zSymmetry = RotationSymmetry(NewRotation(0, -1))
 
 // zCornerIndices are like xCornerIndices but for the Z axis cube.
-var zCornerIndices []int = []int{2, 3, 6, 7, 0, 1, 4, 5}
+var zCornerIndices []int = zSymmetry.Inverse().cornerSlots()
 
 // zEdgeIndices are like xEdgeIndices but for the Z axis cube.
-var zEdgeIndices []int = []int{2, 11, 8, 10, 3, 1, 0, 5, 6, 4, 9, 7}
+var zEdgeIndices []int = zSymmetry.Inverse().edgeSlots()
 
-// zMoveTranslation is like xMoveTranslation, but it's for doing an "x" rotation
-// before applying a move. The mapping is: R->R, L->L, F->U, B->D, U->B, D->F.
-var zMoveTranslation []Move = []Move{3, 2, 0, 1, 4, 5, 9, 8, 6, 7, 10, 11, 15,
-	14, 12, 13, 16, 17}
+// zMoveTranslation is like xMoveTranslation, but for the Z axis cube.
+var zMoveTranslation []Move = zSymmetry.moveTable()
 
 // A Phase1Cube is an efficient way to represent the parts of a cube which
 // matter for the first phase of Kociemba's algorithm.
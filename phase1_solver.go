@@ -1,116 +1,76 @@
 package gocube
 
+import "context"
+
 // Phase1Heuristic stores the data needed to effectively prune the search for a
 // solution for phase-1.
+//
+// The table is indexed by symmetry-reduced coordinates, so that it can store
+// the exact distance of every phase-1 state without using much memory. A
+// Phase1Heuristic must be created by NewPhase1Heuristic or by loading
+// SolverTables.
 type Phase1Heuristic struct {
-	// This stores the number of moves needed to orient the corners and edges.
-	COEO [4478976]uint8
+	// FlipSliceTwist stores the number of moves needed to solve phase-1,
+	// packed two entries per byte. It is indexed by flip-slice class, which
+	// combines the edge orientation with the positions of the slice edges,
+	// and by the conjugated corner orientation. This takes about 70MB.
+	FlipSliceTwist []uint8
 
-	// This stores the number of moves needed orient the edges and put the slice
-	// edges on the slice.
-	EOSlice [1013760]uint8
+	sym *phase1SymTables
 }
 
 // NewPhase1Heuristic generates a heuristic for the phase-1 solver.
 func NewPhase1Heuristic(moves *Phase1Moves) *Phase1Heuristic {
-	res := new(Phase1Heuristic)
-	res.computeCOEO(moves)
-	res.computeEOSlice(moves)
-	return res
+	return NewPhase1HeuristicMetric(moves, HTM)
 }
 
-// LowerBound returns the minimum number of moves needed to solve at least one
-// phase-1 axis.
-func (p *Phase1Heuristic) LowerBound(c *Phase1Cube) int {
-	finalResult := uint8(127)
-
-	coEOValues := []int{
-		c.XCornerOrientation*2048 + c.XEdgeOrientation(),
-		c.YCornerOrientation*2048 + c.FBEdgeOrientation,
-		c.ZCornerOrientation*2048 + c.UDEdgeOrientation,
+// NewPhase1HeuristicMetric generates a heuristic which counts moves in a given
+// metric.
+func NewPhase1HeuristicMetric(moves *Phase1Moves,
+	metric Metric) *Phase1Heuristic {
+	sym := phase1Sym()
+	set := phase1MoveSet(metric)
+	coMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.COMoves[x][m]
+	})
+	eoMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.EOMoves[x][m]
+	})
+	sliceMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.ESliceMoves[x][m]
+	})
+	flipSliceMove := func(x, m int) int {
+		return sliceMove(x/2048, m)*2048 + eoMove(x%2048, m)
 	}
-	sliceValues := []int{
-		c.MSlicePermutation*2048 + c.XEdgeOrientation(),
-		c.ESlicePermutation*2048 + c.FBEdgeOrientation,
-		c.SSlicePermutation*2048 + c.UDEdgeOrientation,
+	table := newSymDistanceTable(sym.flipSlice, flipSliceMove, 2187, coMove,
+		sym.twistConj, set, 220*2048, 1093, true)
+	return &Phase1Heuristic{
+		FlipSliceTwist: table.(nibbleArray),
+		sym:            sym,
 	}
-
-	for axis := 0; axis < 3; axis++ {
-		result := p.COEO[coEOValues[axis]]
-		if r := p.EOSlice[sliceValues[axis]]; r > result {
-			result = r
-		}
-		if result < finalResult {
-			finalResult = result
-		}
-	}
-
-	return int(finalResult)
 }
 
-func (p *Phase1Heuristic) computeCOEO(moves *Phase1Moves) {
-	for i := 0; i < 4478976; i++ {
-		p.COEO[i] = 8
+// LowerBound returns the minimum number of moves needed to solve at least one
+// phase-1 axis.
+func (p *Phase1Heuristic) LowerBound(c *Phase1Cube) int {
+	res := p.axisLowerBound(c.XCornerOrientation, c.XEdgeOrientation(),
+		c.MSlicePermutation)
+	if r := p.axisLowerBound(c.YCornerOrientation, c.FBEdgeOrientation,
+		c.ESlicePermutation); r < res {
+		res = r
 	}
-
-	nodes := []phase1COEONode{{1093, 0, 0}}
-	visited := make([]bool, 4478976)
-	for len(nodes) > 0 {
-		node := nodes[0]
-		nodes = nodes[1:]
-		hash := node.co*2048 + node.eo
-		if p.COEO[hash] != 8 {
-			continue
-		}
-		p.COEO[hash] = node.depth
-
-		if node.depth == 7 {
-			continue
-		}
-
-		for move := 0; move < 18; move++ {
-			newCO := moves.COMoves[node.co][move]
-			newEO := moves.EOMoves[node.eo][move]
-			newHash := newCO*2048 + newEO
-			if !visited[newHash] {
-				nodes = append(nodes, phase1COEONode{newCO, newEO,
-					node.depth + 1})
-				visited[newHash] = true
-			}
-		}
+	if r := p.axisLowerBound(c.ZCornerOrientation, c.UDEdgeOrientation,
+		c.SSlicePermutation); r < res {
+		res = r
 	}
+	return int(res)
 }
 
-func (p *Phase1Heuristic) computeEOSlice(moves *Phase1Moves) {
-	for i := 0; i < 1013760; i++ {
-		p.EOSlice[i] = 8
-	}
-	nodes := []phase1EOSliceNode{{0, 220, 0}}
-	visited := make([]bool, 1013760)
-	for len(nodes) > 0 {
-		node := nodes[0]
-		nodes = nodes[1:]
-		hash := node.slice*2048 + node.eo
-		if p.EOSlice[hash] != 8 {
-			continue
-		}
-		p.EOSlice[hash] = node.depth
-
-		if node.depth == 7 {
-			continue
-		}
-
-		for move := 0; move < 18; move++ {
-			newEO := moves.EOMoves[node.eo][move]
-			newSlice := moves.ESliceMoves[node.slice][move]
-			newHash := newSlice*2048 + newEO
-			if !visited[newHash] {
-				newNode := phase1EOSliceNode{newEO, newSlice, node.depth + 1}
-				nodes = append(nodes, newNode)
-				visited[newHash] = true
-			}
-		}
-	}
+func (p *Phase1Heuristic) axisLowerBound(co, eo, slice int) uint8 {
+	symCount := len(udSymmetries)
+	class, sym := p.sym.flipSlice.class(slice*2048 + eo)
+	idx := class*2187 + int(p.sym.twistConj[co*symCount+sym])
+	return nibbleArray(p.FlipSliceTwist).get(idx)
 }
 
 // A Phase1Solution stores information about a phase-1 solution.
@@ -121,64 +81,85 @@ type Phase1Solution struct {
 
 // A Phase1Solver finds solutions to a specific phase-1 state.
 type Phase1Solver struct {
-	stopped   chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
 	solutions <-chan Phase1Solution
 
 	heuristic *Phase1Heuristic
 	moves     *Phase1Moves
+	moveSet   *moveSet
+	observer  *PhaseObserver
 }
 
 // NewPhase1Solver creates and starts a Phase1Solver.
 func NewPhase1Solver(c Phase1Cube, h *Phase1Heuristic,
 	m *Phase1Moves) *Phase1Solver {
+	ctx, cancel := context.WithCancel(context.Background())
 	solutions := make(chan Phase1Solution)
-	res := &Phase1Solver{make(chan struct{}), solutions, h, m}
+	res := &Phase1Solver{
+		ctx:       ctx,
+		cancel:    cancel,
+		solutions: solutions,
+		heuristic: h,
+		moves:     m,
+		moveSet:   phase1MoveSet(HTM),
+	}
 	go res.search(solutions, c)
 	return res
 }
 
+// Solutions is a channel over which phase-1 solutions are delivered, shortest
+// first.
 func (p *Phase1Solver) Solutions() <-chan Phase1Solution {
 	return p.solutions
 }
 
+// Stop stops the solver. It may be called more than once.
 func (p *Phase1Solver) Stop() {
-	close(p.stopped)
+	p.cancel()
 }
 
-func (p *Phase1Solver) depthFirst(solutions chan<- Phase1Solution, c Phase1Cube,
-	moves []Move, depth int, lastFace int) bool {
+// depthFirst searches for phase-1 solutions of a given length, passing each
+// one to a callback. It returns false if the search was stopped, either by
+// Stop or by the callback returning false.
+//
+// The last argument is the index of the previous search move in the moveSet,
+// or -1 at the start of the search.
+func (p *Phase1Solver) depthFirst(c Phase1Cube, moves []Move, depth int,
+	last int, found func(Phase1Solution) bool) bool {
+	p.observer.Node()
+
 	// If the depth is zero, we may have a solution.
 	if depth == 0 {
 		if c.AnySolved() {
 			res := make([]Move, len(moves))
 			copy(res, moves)
-			select {
-			case <-p.stopped:
-				return false
-			case solutions <- Phase1Solution{c, res}:
-			}
+			return found(Phase1Solution{c, res})
 		}
 		return true
 	}
 
 	// Check the heuristic.
-	if p.heuristic.LowerBound(&c) > depth {
+	if p.heuristic.LowerBound(&c)*p.moveSet.scale > depth {
+		p.observer.Prune()
 		return true
 	}
 
 	// Apply every move and recurse.
-	for m := 0; m < 18; m++ {
-		move := Move(m)
-		if move.Face() == lastFace {
+	allowed := p.moveSet.allowed[last+1]
+	for i, searchMove := range p.moveSet.moves {
+		if !allowed[i] || searchMove.cost > depth {
 			continue
 		}
 		cube := c
-		cube.Move(move, p.moves)
-		moves = append(moves, move)
-		if !p.depthFirst(solutions, cube, moves, depth-1, move.Face()) {
+		for _, m := range searchMove.moves {
+			cube.Move(Move(m), p.moves)
+			moves = append(moves, Move(m))
+		}
+		if !p.depthFirst(cube, moves, depth-searchMove.cost, i, found) {
 			return false
 		}
-		moves = moves[:len(moves)-1]
+		moves = moves[:len(moves)-len(searchMove.moves)]
 		if depth >= 7 && p.isStopped() {
 			return false
 		}
@@ -188,34 +169,26 @@ func (p *Phase1Solver) depthFirst(solutions chan<- Phase1Solution, c Phase1Cube,
 }
 
 func (p *Phase1Solver) isStopped() bool {
-	select {
-	case <-p.stopped:
-		return true
-	default:
-		return false
-	}
+	return p.ctx.Err() != nil
 }
 
 func (p *Phase1Solver) search(solutions chan<- Phase1Solution, c Phase1Cube) {
+	send := func(solution Phase1Solution) bool {
+		select {
+		case <-p.ctx.Done():
+			return false
+		case solutions <- solution:
+			return true
+		}
+	}
 	depth := 0
 	for {
+		p.observer.StartDepth(depth)
 		moves := make([]Move, 0, depth)
-		if !p.depthFirst(solutions, c, moves, depth, 0) {
+		if !p.depthFirst(c, moves, depth, -1, send) {
 			close(solutions)
 			return
 		}
 		depth++
 	}
 }
-
-type phase1COEONode struct {
-	co    int
-	eo    int
-	depth uint8
-}
-
-type phase1EOSliceNode struct {
-	eo    int
-	slice int
-	depth uint8
-}
@@ -32,8 +32,8 @@ func BenchmarkPhase1Solver(b *testing.B) {
 }
 
 func TestPhase1Heuristic(t *testing.T) {
-	table := NewPhase1Moves()
-	heuristic := NewPhase1Heuristic(table)
+	table := sharedSolverTables(HTM).P1Moves
+	heuristic := sharedSolverTables(HTM).P1Heuristic
 
 	// Do random move sequences and ensure that the lower bound is never too
 	// high.
@@ -55,8 +55,8 @@ func TestPhase1Heuristic(t *testing.T) {
 }
 
 func TestPhase1Solver(t *testing.T) {
-	table := NewPhase1Moves()
-	heuristic := NewPhase1Heuristic(table)
+	table := sharedSolverTables(HTM).P1Moves
+	heuristic := sharedSolverTables(HTM).P1Heuristic
 
 	// Do a bunch of random move sequences and make sure a solution is found.
 	for length := 1; length <= 12; length++ {
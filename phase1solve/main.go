@@ -14,19 +14,23 @@ func main() {
 		fmt.Println("Failed to read stickers:", err)
 		os.Exit(1)
 	}
-	cc, err := sc.CubieCube()
-	if err != nil {
+	if err := sc.Validate(); err != nil {
 		fmt.Println("Invalid stickers:", err)
 		os.Exit(1)
 	}
+	cc, _ := sc.CubieCube()
 
-	fmt.Println("Generating data...")
-	moves := gocube.NewPhase1Moves()
-	heuristic := gocube.NewPhase1Heuristic(moves)
+	fmt.Println("Loading tables...")
+	tables, err := gocube.LoadDefaultSolverTables()
+	if err != nil {
+		fmt.Println("Failed to cache tables:", err)
+	}
 
 	fmt.Println("Searching...")
-	solver := gocube.NewPhase1Solver(cc.Phase1Cube(), heuristic, moves)
+	solver := gocube.NewPhase1Solver(cc.Phase1Cube(), tables.P1Heuristic,
+		tables.P1Moves)
 	for solution := range solver.Solutions() {
-		fmt.Println(solution.Moves, "-", len(solution.Moves), "moves")
+		alg := gocube.NewAlgorithm(solution.Moves)
+		fmt.Println(alg, "-", alg.HTM(), "moves")
 	}
 }
@@ -1,12 +1,16 @@
 package gocube
 
-import "errors"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // inverseXCornerIndices is the inverse permutation of xCornerIndices.
-var inverseXCornerIndices []int = []int{2, 0, 3, 1, 6, 4, 7, 5}
+var inverseXCornerIndices []int = xSymmetry.cornerSlots()
 
 // inverseZCornerIndices is the inverse permutation of zCornerIndices.
-var inverseZCornerIndices []int = []int{4, 5, 0, 1, 6, 7, 2, 3}
+var inverseZCornerIndices []int = zSymmetry.cornerSlots()
 
 // A Phase2Cube represents the parts of a cube that are important for phase-2
 // solving.
@@ -23,9 +27,24 @@ type Phase2Cube struct {
 
 // NewPhase2Cube generates a Phase2Cube from a CubieCube.
 // The axis argument is 0 for X axis, 1 for Y axis, or 2 for Z axis.
-// If the cube is not reduced to phase-2 in the given axis, this may return an
-// error, but it does not validate everything.
+//
+// If the cube is invalid, this returns one of the errors from
+// CubieCube.Validate. If it is not reduced to phase-2 in the given axis, this
+// returns a *Phase2Error.
 func NewPhase2Cube(c CubieCube, axis int) (Phase2Cube, error) {
+	var res Phase2Cube
+	if err := c.Validate(); err != nil {
+		return res, err
+	} else if err := checkPhase2Reduced(&c, axis); err != nil {
+		return res, err
+	}
+	return encodePhase2Cube(&c, axis), nil
+}
+
+// encodePhase2Cube is like NewPhase2Cube, but it assumes that the cube is
+// valid and reduced to phase-2 in the axis, so that solvers can use it for
+// every phase-1 solution without checking the cube again.
+func encodePhase2Cube(c *CubieCube, axis int) Phase2Cube {
 	var res Phase2Cube
 	if axis == 0 {
 		res.CornerPermutation = encodeXCornerPerm(&c.Corners)
@@ -40,12 +59,82 @@ func NewPhase2Cube(c CubieCube, axis int) (Phase2Cube, error) {
 		res.EdgePermutation = encodeFBEdges(&c.Edges)
 		res.SlicePermutation = encodeSSlicePerm(&c.Edges)
 	}
-	if res.EdgePermutation < 0 {
-		return res, errors.New("invalid edge permutation")
-	} else if res.SlicePermutation < 0 {
-		return res, errors.New("invalid slice permutation")
+	return res
+}
+
+// A Phase2Error indicates that a cube is not reduced to phase-2 on an axis.
+type Phase2Error struct {
+	// Axis is 0, 1, or 2 for the X, Y, or Z axis.
+	Axis int
+
+	// Corners lists the corner slots whose pieces are twisted relative to
+	// the axis.
+	Corners []int
+
+	// Edges lists the edge slots whose pieces are flipped relative to the
+	// axis, or whose pieces belong in the axis's slice but are not in it.
+	Edges []int
+}
+
+func (p *Phase2Error) Error() string {
+	var problems []string
+	if len(p.Corners) > 0 {
+		problems = append(problems, "corners "+slotNames(CornerKind, p.Corners))
+	}
+	if len(p.Edges) > 0 {
+		problems = append(problems, "edges "+slotNames(EdgeKind, p.Edges))
+	}
+	return fmt.Sprintf("cube is not reduced to phase-2 on the %s axis "+
+		"(unreduced %s)", []string{"X", "Y", "Z"}[p.Axis],
+		strings.Join(problems, " and "))
+}
+
+// checkPhase2Reduced returns a *Phase2Error if a valid cube is not reduced to
+// phase-2 on an axis.
+//
+// The cube is conjugated so that the axis becomes the Y axis, where a reduced
+// cube has no twisted corners, no flipped edges, and the E slice edges in the
+// E slice.
+func checkPhase2Reduced(c *CubieCube, axis int) error {
+	// The slot tables map the slots of the conjugated cube back to the slots
+	// of the original cube.
+	cube := *c
+	var cornerSlots, edgeSlots []int
+	if axis == 0 {
+		cube = c.Conjugate(xSymmetry.Inverse())
+		cornerSlots, edgeSlots = xSymmetry.cornerSlots(), xSymmetry.edgeSlots()
+	} else if axis == 2 {
+		cube = c.Conjugate(zSymmetry.Inverse())
+		cornerSlots, edgeSlots = zSymmetry.cornerSlots(), zSymmetry.edgeSlots()
+	}
+
+	res := &Phase2Error{Axis: axis}
+	for slot, corner := range cube.Corners {
+		if corner.Orientation != 1 {
+			if cornerSlots != nil {
+				slot = cornerSlots[slot]
+			}
+			res.Corners = append(res.Corners, slot)
+		}
+	}
+	isSlice := func(slot int) bool {
+		return slot == EdgeFR || slot == EdgeFL || slot == EdgeBR ||
+			slot == EdgeBL
+	}
+	for slot, edge := range cube.Edges {
+		if edge.Flip || isSlice(slot) != isSlice(edge.Piece) {
+			if edgeSlots != nil {
+				slot = edgeSlots[slot]
+			}
+			res.Edges = append(res.Edges, slot)
+		}
+	}
+	if len(res.Corners) > 0 || len(res.Edges) > 0 {
+		sort.Ints(res.Corners)
+		sort.Ints(res.Edges)
+		return res
 	}
-	return res, nil
+	return nil
 }
 
 // SolvedPhase2Cube returns a solved Phase2Cube.
@@ -1,6 +1,10 @@
 package gocube
 
-import "testing"
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
 
 func BenchmarkNewPhase2Cube(b *testing.B) {
 	scramble, _ := ParseMoves("U2 L F2 R2 D2 R' B2 U2 D2 R2 L' F2 R U2 L U2 R2")
@@ -80,6 +84,35 @@ func TestNewPhase2Cube(t *testing.T) {
 	}
 }
 
+func TestNewPhase2CubeErrors(t *testing.T) {
+	cube := SolvedCubieCube()
+	cube.Move(NewMove(1, 1))
+	_, err := NewPhase2Cube(cube, 0)
+	var p2Err *Phase2Error
+	if !errors.As(err, &p2Err) {
+		t.Fatalf("expected *Phase2Error but got %v", err)
+	}
+	expected := Phase2Error{
+		Axis:    0,
+		Corners: []int{CornerUBL, CornerURB, CornerULF, CornerUFR},
+		Edges:   []int{EdgeUF, EdgeUL, EdgeUR, EdgeUB},
+	}
+	if !reflect.DeepEqual(*p2Err, expected) {
+		t.Errorf("expected %v but got %v", expected, *p2Err)
+	}
+	if _, err := NewPhase2Cube(cube, 1); err != nil {
+		t.Errorf("unexpected error on the Y axis: %v", err)
+	}
+
+	cube = SolvedCubieCube()
+	cube.Edges[0].Flip = true
+	_, err = NewPhase2Cube(cube, 1)
+	var flipErr *FlipError
+	if !errors.As(err, &flipErr) {
+		t.Errorf("expected *FlipError but got %v", err)
+	}
+}
+
 func TestPhase2Moves(t *testing.T) {
 	// Do the algorithm "R2 U F2 D2 L2 D' B2 R2 L2 D2 U' F2 D R2 U R2 D2"
 	moves := []Phase2Move{2, 4, 0, 9, 3, 8, 1, 2, 3, 9, 5, 0, 7, 2, 4, 2, 9}
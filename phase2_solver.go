@@ -1,100 +1,202 @@
 package gocube
 
+import (
+	"context"
+	"math"
+	"sync"
+)
+
 // A Phase2Heuristic estimates a lower bound for the number of moves to solve a
 // Phase2Cube.
+//
+// The tables are indexed by symmetry-reduced coordinates, so that they can
+// store the exact distance of every state without using much memory. A
+// Phase2Heuristic must be created by NewPhase2Heuristic or by loading
+// SolverTables.
 type Phase2Heuristic struct {
-	// If an element is -1, it should be assumed to have the value 12.
-	CornersSlice [967680]int8
+	// This stores the number of moves needed to solve the corners and the
+	// slice, indexed by corner permutation class and conjugated slice.
+	CornersSlice []uint8
+
+	// This stores the number of moves needed to solve the U and D edges and
+	// the slice, indexed by edge permutation class and conjugated slice.
+	EdgesSlice []uint8
 
-	// If an element is -1, it should be assumed to have the value 9.
-	EdgesSlice [967680]int8
+	sym *phase2SymTables
 }
 
 // NewPhase2Heuristic generates a Phase2Heuristic.
-// If complete is true, the full index is found. Otherwise, corners will only
-// be searched up to depth 11, and edges will only be searched up to depth 8.
-func NewPhase2Heuristic(moves *Phase2Moves, complete bool) *Phase2Heuristic {
-	res := new(Phase2Heuristic)
-
-	// Make all the move counts -1 by default.
-	for i := 0; i < 967680; i++ {
-		res.CornersSlice[i] = -1
-		res.EdgesSlice[i] = -1
+func NewPhase2Heuristic(moves *Phase2Moves) *Phase2Heuristic {
+	return NewPhase2HeuristicMetric(moves, HTM)
+}
+
+// NewPhase2HeuristicMetric generates a Phase2Heuristic which counts moves in a
+// given metric.
+func NewPhase2HeuristicMetric(moves *Phase2Moves,
+	metric Metric) *Phase2Heuristic {
+	sym := phase2Sym()
+	set := phase2MoveSet(metric, 1)
+	cornerMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.CornerMoves[x][m]
+	})
+	edgeMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.EdgeMoves[x][m]
+	})
+	sliceMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.SliceMoves[x][m]
+	})
+	return &Phase2Heuristic{
+		CornersSlice: newSymDistanceTable(sym.corners, cornerMove, 24,
+			sliceMove, sym.sliceConj, set, 0, 0, false).(byteTable),
+		EdgesSlice: newSymDistanceTable(sym.edges, edgeMove, 24, sliceMove,
+			sym.sliceConj, set, 0, 0, false).(byteTable),
+		sym: sym,
 	}
+}
 
-	// Generate CornersSlice
-	nodes := []phase2Node{{0, 0, 0}}
-	visited := make([]bool, 967680)
-	for len(nodes) > 0 {
-		node := nodes[0]
-		nodes = nodes[1:]
-		if res.CornersSlice[node.hash()] >= 0 {
-			continue
-		}
-		res.CornersSlice[node.hash()] = node.depth
-		if !complete && node.depth == 11 {
-			continue
-		}
-		for m := 0; m < 10; m++ {
-			p4 := moves.SliceMoves[node.perm4][m]
-			p8 := moves.CornerMoves[node.perm8][m]
-			newNode := phase2Node{p4, p8, node.depth + 1}
-			if visited[newNode.hash()] {
-				continue
-			} else {
-				visited[newNode.hash()] = true
-			}
-			nodes = append(nodes, newNode)
-		}
+// LowerBound returns the heuristic lower bound for a given Phase2Cube.
+func (p *Phase2Heuristic) LowerBound(c *Phase2Cube) int {
+	symCount := len(udSymmetries)
+	cornerClass, cornerSym := p.sym.corners.class(c.CornerPermutation)
+	edgeClass, edgeSym := p.sym.edges.class(c.EdgePermutation)
+
+	res := p.CornersSlice[cornerClass*24+
+		int(p.sym.sliceConj[c.SlicePermutation*symCount+cornerSym])]
+	if r := p.EdgesSlice[edgeClass*24+
+		int(p.sym.sliceConj[c.SlicePermutation*symCount+edgeSym])]; r > res {
+		res = r
 	}
+	return int(res)
+}
 
-	// Generate EdgesSlice
-	nodes = []phase2Node{{0, 0, 0}}
-	visited = make([]bool, 967680)
-	for len(nodes) > 0 {
-		node := nodes[0]
-		nodes = nodes[1:]
-		if res.EdgesSlice[node.hash()] >= 0 {
-			continue
-		}
-		res.EdgesSlice[node.hash()] = node.depth
-		if !complete && node.depth == 8 {
-			continue
+// A phase2Bound gives a lower bound on the cost of solving a Phase2Cube.
+type phase2Bound interface {
+	LowerBound(c *Phase2Cube) int
+}
+
+// A phase2CostTable is a phase2Bound for a weighted moveSet.
+//
+// Unlike a Phase2Heuristic, the tables are not symmetry-reduced, since the
+// costs may not be symmetric. States which cannot be solved with the moves
+// have no bound.
+type phase2CostTable struct {
+	cornersSlice byteTable
+	edgesSlice   byteTable
+}
+
+// newPhase2CostTable builds the tables for a weighted moveSet. It returns nil
+// if ctx is done first.
+func newPhase2CostTable(ctx context.Context, moves *Phase2Moves,
+	set *moveSet) *phase2CostTable {
+	costs := make([]uint8, len(set.moves))
+	for i, m := range set.moves {
+		costs[i] = 0xfe
+		if m.cost < 0xfe {
+			costs[i] = uint8(m.cost)
 		}
-		for m := 0; m < 10; m++ {
-			p4 := moves.SliceMoves[node.perm4][m]
-			p8 := moves.EdgeMoves[node.perm8][m]
-			newNode := phase2Node{p4, p8, node.depth + 1}
-			if visited[newNode.hash()] {
-				continue
-			} else {
-				visited[newNode.hash()] = true
-			}
-			nodes = append(nodes, newNode)
+	}
+	sliceMove := searchMoveFunc(set, func(x, m int) int {
+		return moves.SliceMoves[x][m]
+	})
+	table := func(permMove func(x, m int) int) byteTable {
+		res := newByteTable(40320*24, 0xff)
+		if !fillWeightedDistances(ctx, res, len(res), 0, costs, 0xff,
+			func(idx int, neighbors []int) {
+				perm, slice := idx/24, idx%24
+				for m := range neighbors {
+					neighbors[m] = permMove(perm, m)*24 + sliceMove(slice, m)
+				}
+			}) {
+			return nil
 		}
+		return res
+	}
+	res := &phase2CostTable{
+		cornersSlice: table(searchMoveFunc(set, func(x, m int) int {
+			return moves.CornerMoves[x][m]
+		})),
+	}
+	if res.cornersSlice == nil {
+		return nil
+	}
+	res.edgesSlice = table(searchMoveFunc(set, func(x, m int) int {
+		return moves.EdgeMoves[x][m]
+	}))
+	if res.edgesSlice == nil {
+		return nil
 	}
-
 	return res
 }
 
-// LowerBound returns the heuristic lower bound for a given Phase2Cube.
-func (p *Phase2Heuristic) LowerBound(c *Phase2Cube) int {
-	cornersSlice := c.CornerPermutation*24 + c.SlicePermutation
-	edgesSlice := c.EdgePermutation*24 + c.SlicePermutation
-	cMoves := p.CornersSlice[cornersSlice]
-	eMoves := p.EdgesSlice[edgesSlice]
-
-	if cMoves < 0 {
-		cMoves = 12
+func (p *phase2CostTable) LowerBound(c *Phase2Cube) int {
+	res := p.cornersSlice[c.CornerPermutation*24+c.SlicePermutation]
+	if r := p.edgesSlice[c.EdgePermutation*24+c.SlicePermutation]; r > res {
+		res = r
 	}
-	if eMoves < 0 {
-		eMoves = 9
+	if res == 0xff {
+		return math.MaxInt32
+	}
+	return int(res)
+}
+
+// A phase2CostCache stores the phase2CostTables which solvers have built with
+// a set of SolverTables. They are keyed by the costs of the base moves of a
+// phase-2 moveSet, so axes whose moves cost the same share a table.
+type phase2CostCache struct {
+	lock    sync.Mutex
+	entries map[[10]int]*phase2CostEntry
+}
+
+// A phase2CostEntry is a table which one solver builds while others wait for
+// it. The table is nil if the build was canceled.
+type phase2CostEntry struct {
+	done  chan struct{}
+	table *phase2CostTable
+}
+
+// get returns the table for a phase-2 moveSet which was weighted by costs,
+// building it unless another solver already is. It returns nil if ctx is done
+// first.
+func (p *phase2CostCache) get(ctx context.Context, moves *Phase2Moves,
+	set *moveSet, costs []int) *phase2CostTable {
+	var key [10]int
+	for i, m := range set.base {
+		key[i] = costs[m]
 	}
+	for {
+		p.lock.Lock()
+		entry, ok := p.entries[key]
+		if !ok {
+			entry = &phase2CostEntry{done: make(chan struct{})}
+			p.entries[key] = entry
+		}
+		p.lock.Unlock()
+
+		if !ok {
+			entry.table = newPhase2CostTable(ctx, moves, set)
+			if entry.table == nil {
+				// A solver which is still running builds it next time.
+				p.lock.Lock()
+				delete(p.entries, key)
+				p.lock.Unlock()
+			}
+			close(entry.done)
+			return entry.table
+		}
 
-	if eMoves > cMoves {
-		return int(eMoves)
-	} else {
-		return int(cMoves)
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			// A finished table is still returned.
+			select {
+			case <-entry.done:
+			default:
+				return nil
+			}
+		}
+		if entry.table != nil {
+			return entry.table
+		}
 	}
 }
 
@@ -102,47 +204,63 @@ func (p *Phase2Heuristic) LowerBound(c *Phase2Cube) int {
 // maxLen moves.
 func SolvePhase2(cube Phase2Cube, maxLen int, heuristic *Phase2Heuristic,
 	moves *Phase2Moves) []Phase2Move {
-	for depth := 0; depth <= maxLen; depth++ {
-		if x := depthFirstPhase2(cube, depth, heuristic, moves, 0); x != nil {
+	return solvePhase2(context.Background(), cube, maxLen, heuristic, moves,
+		phase2MoveSet(HTM, 1), nil)
+}
+
+// solvePhase2 is like SolvePhase2, but it measures solutions using the costs
+// of a moveSet, which the heuristic must account for. It gives up if ctx is
+// done, and it reports its progress to observer, which may be nil.
+func solvePhase2(ctx context.Context, cube Phase2Cube, maxLen int,
+	heuristic phase2Bound, moves *Phase2Moves, set *moveSet,
+	observer *PhaseObserver) []Phase2Move {
+	defer observer.EndSearch(observer.StartSearch())
+	for depth := 0; depth <= maxLen && ctx.Err() == nil; depth++ {
+		observer.StartDepth(depth)
+		if x := depthFirstPhase2(ctx, cube, depth, heuristic, moves, set,
+			observer, -1); x != nil {
 			return x
 		}
 	}
 	return nil
 }
 
-func depthFirstPhase2(cube Phase2Cube, depth int, heuristic *Phase2Heuristic,
-	moves *Phase2Moves, lastFace int) []Phase2Move {
+func depthFirstPhase2(ctx context.Context, cube Phase2Cube, depth int,
+	heuristic phase2Bound, moves *Phase2Moves, set *moveSet,
+	observer *PhaseObserver, last int) []Phase2Move {
+	observer.Node()
 	if depth == 0 {
 		if cube.Solved() {
 			return []Phase2Move{}
 		}
+		return nil
 	} else if heuristic.LowerBound(&cube) > depth {
+		observer.Prune()
+		return nil
+	} else if depth >= 7 && ctx.Err() != nil {
 		return nil
 	}
 
 	// Apply moves and recurse.
-	for i := 0; i < 10; i++ {
-		m := Phase2Move(i)
-		if m.Face() == lastFace {
+	allowed := set.allowed[last+1]
+	for i, searchMove := range set.moves {
+		if !allowed[i] || searchMove.cost > depth {
 			continue
 		}
 		c := cube
-		c.Move(m, moves)
-		res := depthFirstPhase2(c, depth-1, heuristic, moves, m.Face())
+		for _, m := range searchMove.moves {
+			c.Move(Phase2Move(m), moves)
+		}
+		res := depthFirstPhase2(ctx, c, depth-searchMove.cost, heuristic,
+			moves, set, observer, i)
 		if res != nil {
-			return append([]Phase2Move{m}, res...)
+			prefix := make([]Phase2Move, len(searchMove.moves))
+			for j, m := range searchMove.moves {
+				prefix[j] = Phase2Move(m)
+			}
+			return append(prefix, res...)
 		}
 	}
 
 	return nil
 }
-
-type phase2Node struct {
-	perm4 int
-	perm8 int
-	depth int8
-}
-
-func (n *phase2Node) hash() int {
-	return n.perm8*24 + n.perm4
-}
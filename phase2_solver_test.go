@@ -1,23 +1,16 @@
 package gocube
 
 import (
+	"context"
 	"math/rand"
 	"testing"
 )
 
-func BenchmarkPhase2HeuristicComplete(b *testing.B) {
+func BenchmarkNewPhase2Heuristic(b *testing.B) {
 	moves := NewPhase2Moves()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		NewPhase2Heuristic(moves, true)
-	}
-}
-
-func BenchmarkPhase2HeuristicIncomplete(b *testing.B) {
-	moves := NewPhase2Moves()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		NewPhase2Heuristic(moves, false)
+		NewPhase2Heuristic(moves)
 	}
 }
 
@@ -32,7 +25,7 @@ func BenchmarkPhase2Move(b *testing.B) {
 
 func BenchmarkSolvePhase2(b *testing.B) {
 	moves := NewPhase2Moves()
-	heuristic := NewPhase2Heuristic(moves, false)
+	heuristic := NewPhase2Heuristic(moves)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
@@ -47,7 +40,7 @@ func BenchmarkSolvePhase2(b *testing.B) {
 
 func TestSolvePhase2(t *testing.T) {
 	table := NewPhase2Moves()
-	heuristic := NewPhase2Heuristic(table, false)
+	heuristic := NewPhase2Heuristic(table)
 
 	// Do a bunch of random move sequences and make sure a solution is found.
 	for length := 1; length <= 18; length++ {
@@ -83,3 +76,34 @@ func TestSolvePhase2(t *testing.T) {
 		}
 	}
 }
+
+func TestPhase2CostCache(t *testing.T) {
+	moves := NewPhase2Moves()
+	costs := make([]int, 18)
+	for m := range costs {
+		costs[m] = 1 + Move(m).Face()%2
+	}
+	set := phase2MoveSet(HTM, 1).weighted(costs)
+	cache := &phase2CostCache{entries: map[[10]int]*phase2CostEntry{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if table := cache.get(ctx, moves, set, costs); table != nil {
+		t.Fatal("canceled build returned a table")
+	}
+	if len(cache.entries) != 0 {
+		t.Fatal("canceled build was cached")
+	}
+
+	table := cache.get(context.Background(), moves, set, costs)
+	if table == nil {
+		t.Fatal("no table was built")
+	}
+	if cache.get(ctx, moves, set, costs) != table {
+		t.Error("table was not shared")
+	}
+	cube := SolvedPhase2Cube()
+	if bound := table.LowerBound(&cube); bound != 0 {
+		t.Errorf("expected bound 0 for solved cube but got %d", bound)
+	}
+}
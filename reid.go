@@ -0,0 +1,126 @@
+package gocube
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReidEdgeSlots lists the edge slots in the order used by Reid's positional
+// notation: UF UR UB UL DF DR DB DL FR FL BR BL.
+var ReidEdgeSlots = []int{EdgeUF, EdgeUR, EdgeUB, EdgeUL, EdgeDF, EdgeDR,
+	EdgeDB, EdgeDL, EdgeFR, EdgeFL, EdgeBR, EdgeBL}
+
+// ReidCornerSlots lists the corner slots in the order used by Reid's
+// positional notation: UFR URB UBL ULF DRF DFL DLB DBR.
+var ReidCornerSlots = []int{CornerUFR, CornerURB, CornerUBL, CornerULF,
+	CornerDRF, CornerDFL, CornerDLB, CornerDBR}
+
+// ParseReidString parses a state in the positional notation used by Reid's
+// optimal solver.
+//
+// The input lists the twelve edges and then the eight corners, separated by
+// whitespace. For each slot in the order of ReidEdgeSlots and ReidCornerSlots,
+// it gives the piece in that slot by naming the piece's stickers in the same
+// order as the faces in the slot's name. The solved cube is written as:
+//
+//	UF UR UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DBR
+//
+// If a field names an impossible piece, the returned error is a
+// *ReidPieceError. The resulting cube is validated, so any error from
+// CubieCube.Validate may also be returned.
+func ParseReidString(str string) (*CubieCube, error) {
+	fields := strings.Fields(str)
+	if len(fields) != 20 {
+		return nil, fmt.Errorf("expected 20 pieces but got %d", len(fields))
+	}
+
+	stickers := SolvedStickerCube()
+	for i, field := range fields {
+		kind, slot := reidSlot(i)
+		name := kind.SlotName(slot)
+		if len(field) != len(name) {
+			return nil, fmt.Errorf("expected %s piece at %s but got %q",
+				kind, name, field)
+		}
+		for j, face := range name {
+			color := faceNumber(rune(field[j]))
+			if color == 0 {
+				return nil, fmt.Errorf("invalid face %q in %s at %s",
+					field[j], field, name)
+			}
+			stickers[reidStickerIndex(kind, slot, face)] = color
+		}
+	}
+
+	res, err := stickers.CubieCube()
+	if err != nil {
+		if stickerErr, ok := err.(*StickerError); ok {
+			field := fields[reidFieldIndex(stickerErr.Kind, stickerErr.Slot)]
+			return nil, &ReidPieceError{Field: field, Err: stickerErr}
+		}
+		return nil, err
+	}
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// A ReidPieceError indicates that a field of a Reid string does not name a
+// piece. It wraps the *StickerError for the field's slot.
+type ReidPieceError struct {
+	// Field is the field as it was written.
+	Field string
+
+	Err *StickerError
+}
+
+func (r *ReidPieceError) Error() string {
+	return "impossible " + r.Err.Kind.String() + " " + r.Field + " at " +
+		r.Err.Kind.SlotName(r.Err.Slot)
+}
+
+// Unwrap returns the *StickerError.
+func (r *ReidPieceError) Unwrap() error {
+	return r.Err
+}
+
+// ReidString converts the cube to the positional notation described in
+// ParseReidString.
+func (c *CubieCube) ReidString() string {
+	stickers := c.StickerCube()
+	fields := make([]string, 20)
+	for i := range fields {
+		kind, slot := reidSlot(i)
+		for _, face := range kind.SlotName(slot) {
+			color := stickers[reidStickerIndex(kind, slot, face)]
+			fields[i] += faceName(color)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// reidSlot returns the slot described by a field of a Reid string.
+func reidSlot(field int) (PieceKind, int) {
+	if field < 12 {
+		return EdgeKind, ReidEdgeSlots[field]
+	}
+	return CornerKind, ReidCornerSlots[field-12]
+}
+
+// reidFieldIndex is the inverse of reidSlot.
+func reidFieldIndex(kind PieceKind, slot int) int {
+	if kind == EdgeKind {
+		return listIndex(ReidEdgeSlots, slot)
+	}
+	return listIndex(ReidCornerSlots, slot) + 12
+}
+
+// reidStickerIndex returns the index in a StickerCube of the sticker of a slot
+// which faces a given face.
+func reidStickerIndex(kind PieceKind, slot int, face rune) int {
+	if kind == CornerKind {
+		return CornerIndexes[slot*3+faceAxis(face)]
+	}
+	return EdgeIndexes[slot*2+strings.IndexRune(EdgeNames[slot], face)]
+}
@@ -0,0 +1,73 @@
+package gocube
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReidString(t *testing.T) {
+	solved := "UF UR UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DBR"
+	cube := SolvedCubieCube()
+	if s := cube.ReidString(); s != solved {
+		t.Errorf("unexpected solved string: %s", s)
+	}
+
+	// The state after an R move.
+	cube.Move(NewMove(5, 1))
+	expected := "UF FR UB UL DF BR DB DL DR FL UR BL FDR FRU UBL ULF BRD DFL " +
+		"DLB BUR"
+	if s := cube.ReidString(); s != expected {
+		t.Errorf("unexpected string for R: %s", s)
+	}
+	parsed, err := ParseReidString(expected)
+	if err != nil {
+		t.Fatal(err)
+	} else if *parsed != cube {
+		t.Error("R did not round trip")
+	}
+
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		parsed, err := ParseReidString(cube.ReidString())
+		if err != nil {
+			t.Fatal(err)
+		} else if *parsed != cube {
+			t.Error("random cube did not round trip")
+		}
+	}
+}
+
+func TestParseReidStringErrors(t *testing.T) {
+	cases := map[string]string{
+		"UF UR UB UL DF DR DB DL FR FL BR":                                    "expected 20 pieces but got 11",
+		"UF UR UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DB":  "expected corner piece at DBR but got \"DB\"",
+		"UF UR UB UL DF DR DB DL FR FL BR BX UFR URB UBL ULF DRF DFL DLB DBR": "invalid face 'X' in BX at BL",
+		"UF UR UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DRB": "impossible corner DRB at DBR",
+		"UF UR UB UL DF DR DB DL FR FL BR UU UFR URB UBL ULF DRF DFL DLB DBR": "impossible edge UU at BL",
+		"UF UR UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB RDB": "corner twists add up to 2 (twisted corners: DBR)",
+		"FU UR UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DBR": "an odd number of edges are flipped (flipped edges: UF)",
+		"UR UF UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DBR": "corner permutation is even but edge permutation is odd",
+		"UF UF UB UL DF DR DB DL FR FL BR BL UFR URB UBL ULF DRF DFL DLB DBR": "edge UF appears in multiple slots: UF, UR",
+	}
+	for input, expected := range cases {
+		_, err := ParseReidString(input)
+		if err == nil {
+			t.Errorf("expected error for %q", input)
+		} else if err.Error() != expected {
+			t.Errorf("unexpected error for %q: %s (expected %s)", input,
+				err.Error(), expected)
+		}
+	}
+}
+
+func TestParseReidStringStickerError(t *testing.T) {
+	_, err := ParseReidString("UF UR UB UL DF DR DB DL FR FL BR UU UFR URB UBL " +
+		"ULF DRF DFL DLB DBR")
+	var stickerErr *StickerError
+	if !errors.As(err, &stickerErr) {
+		t.Fatalf("expected a *StickerError but got %v", err)
+	}
+	if stickerErr.Kind != EdgeKind || stickerErr.Slot != EdgeBL {
+		t.Errorf("unexpected sticker error: %v", stickerErr)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -10,7 +11,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: scrambler <count> [maxlen=30]")
+		fmt.Fprintln(os.Stderr, "Usage: scrambler <count> [maxlen=30] [image_prefix]")
 		os.Exit(1)
 	}
 	count, err := strconv.Atoi(os.Args[1])
@@ -27,24 +28,47 @@ func main() {
 		}
 	}
 
-	p1Moves := gocube.NewPhase1Moves()
-	p1Heuristic := gocube.NewPhase1Heuristic(p1Moves)
-	p2Moves := gocube.NewPhase2Moves()
-	p2Heuristic := gocube.NewPhase2Heuristic(p2Moves, false)
-	tables := gocube.SolverTables{
-		P1Heuristic: p1Heuristic,
-		P1Moves:     p1Moves,
-		P2Heuristic: p2Heuristic,
-		P2Moves:     p2Moves,
+	tables, err := gocube.LoadDefaultSolverTables()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to cache tables:", err)
+	}
+	opts := gocube.SolveOptions{
+		MaxLength:    maxLen,
+		TargetLength: maxLen,
+		Tables:       tables,
 	}
 	for i := 0; i < count; i++ {
 		state := gocube.RandomCubieCube()
-		solver := gocube.NewSolverTables(state, maxLen, tables)
-		for solution := range solver.Solutions() {
-			str := fmt.Sprint(solution)
-			fmt.Println(str[1 : len(str)-1])
-			solver.Stop()
-			break
+		res := gocube.Solve(context.Background(), state, opts)
+		if res.Solution == nil {
+			fmt.Fprintln(os.Stderr, "No scramble found:", res.Reason)
+			os.Exit(1)
 		}
+		solution := res.Solution
+		fmt.Println(gocube.NewAlgorithm(solution))
+		if len(os.Args) > 3 {
+			path := os.Args[3] + strconv.Itoa(i+1) + ".png"
+			if err := writeImage(path, solution); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// writeImage saves a thumbnail of the state produced by a scramble.
+func writeImage(path string, scramble []gocube.Move) error {
+	cube := gocube.SolvedCubieCube()
+	for _, m := range scramble {
+		cube.Move(m)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := cube.WritePNG(f, nil); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
@@ -0,0 +1,162 @@
+package gocube
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Phases of the two-phase Solver, as reported to a SearchObserver.
+const (
+	SearchPhase1 = 0
+	SearchPhase2 = 1
+)
+
+// A SearchObserver collects statistics from a search while it runs, so that
+// its progress can be displayed or benchmarked.
+//
+// A search is divided into numbered phases, each of which reports to its own
+// PhaseObserver. For a Solver, these are SearchPhase1 and SearchPhase2. Other
+// searches document their phases.
+//
+// A SearchObserver may be shared by several goroutines, and its Stats may be
+// read at any time.
+type SearchObserver struct {
+	// OnDepth, if it is non-nil, is called whenever a phase starts to search
+	// a new depth. It is called from the search goroutines, so it should
+	// return quickly. Inner phases, like phase-2 of a Solver, may start new
+	// depths very often.
+	OnDepth func(phase, depth int)
+
+	lock   sync.Mutex
+	phases []*PhaseObserver
+}
+
+// Phase returns the PhaseObserver for a phase, creating it if necessary.
+// It returns nil if o is nil, so that a search can report to it either way.
+func (o *SearchObserver) Phase(phase int) *PhaseObserver {
+	if o == nil {
+		return nil
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for len(o.phases) <= phase {
+		o.phases = append(o.phases, &PhaseObserver{
+			observer: o,
+			phase:    len(o.phases),
+		})
+	}
+	return o.phases[phase]
+}
+
+// Stats returns the statistics for each phase so far, indexed by phase.
+func (o *SearchObserver) Stats() []PhaseStats {
+	o.lock.Lock()
+	phases := append([]*PhaseObserver{}, o.phases...)
+	o.lock.Unlock()
+	res := make([]PhaseStats, len(phases))
+	for i, p := range phases {
+		res[i] = p.Stats()
+	}
+	return res
+}
+
+// PhaseStats stores the statistics for one phase of a search.
+type PhaseStats struct {
+	// Depth is the depth which was most recently started.
+	Depth int
+
+	// Nodes is the number of states which were visited.
+	Nodes int64
+
+	// Prunes is the number of visited states which were cut off by a
+	// heuristic.
+	Prunes int64
+
+	// Searches is the number of times the phase was started. For phase-2 of
+	// a Solver, this is the number of phase-1 solutions it tried to finish.
+	Searches int64
+
+	// Time is the time spent in the phase. When several goroutines search
+	// the same phase, it is the sum of their times, so it may be longer than
+	// the wall time.
+	Time time.Duration
+}
+
+// A PhaseObserver counts the work done by one phase of a search. Every method
+// may be called on a nil PhaseObserver, in which case it does nothing.
+type PhaseObserver struct {
+	observer *SearchObserver
+	phase    int
+
+	// These fields are accessed atomically.
+	depth    int64
+	nodes    int64
+	prunes   int64
+	searches int64
+	time     int64
+}
+
+// StartDepth records that the phase has started to search a new depth.
+func (p *PhaseObserver) StartDepth(depth int) {
+	if p == nil {
+		return
+	}
+	atomic.StoreInt64(&p.depth, int64(depth))
+	if p.observer.OnDepth != nil {
+		p.observer.OnDepth(p.phase, depth)
+	}
+}
+
+// Node records that a state was visited.
+func (p *PhaseObserver) Node() {
+	if p != nil {
+		atomic.AddInt64(&p.nodes, 1)
+	}
+}
+
+// Prune records that a visited state was cut off by a heuristic.
+func (p *PhaseObserver) Prune() {
+	if p != nil {
+		atomic.AddInt64(&p.prunes, 1)
+	}
+}
+
+// StartSearch records that the phase was started, and returns the time to
+// pass to EndSearch.
+func (p *PhaseObserver) StartSearch() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	atomic.AddInt64(&p.searches, 1)
+	return time.Now()
+}
+
+// EndSearch adds the time since a call to StartSearch to the phase.
+func (p *PhaseObserver) EndSearch(start time.Time) {
+	if p != nil {
+		p.AddTime(time.Since(start))
+	}
+}
+
+// AddTime adds time to the phase. The time may be negative, to remove time
+// spent in another phase.
+func (p *PhaseObserver) AddTime(d time.Duration) {
+	if p != nil {
+		atomic.AddInt64(&p.time, int64(d))
+	}
+}
+
+// Stats returns the phase's statistics so far.
+func (p *PhaseObserver) Stats() PhaseStats {
+	if p == nil {
+		return PhaseStats{}
+	}
+	return PhaseStats{
+		Depth:    int(atomic.LoadInt64(&p.depth)),
+		Nodes:    atomic.LoadInt64(&p.nodes),
+		Prunes:   atomic.LoadInt64(&p.prunes),
+		Searches: atomic.LoadInt64(&p.searches),
+		Time:     time.Duration(atomic.LoadInt64(&p.time)),
+	}
+}
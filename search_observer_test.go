@@ -0,0 +1,72 @@
+package gocube
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestSearchObserver(t *testing.T) {
+	cube := SolvedCubieCube()
+	moves, _ := ParseMoves("F R' D2 L U B")
+	for _, m := range moves {
+		cube.Move(m)
+	}
+
+	var lock sync.Mutex
+	var depths []int
+	observer := &SearchObserver{}
+	observer.OnDepth = func(phase, depth int) {
+		if phase == SearchPhase1 {
+			lock.Lock()
+			depths = append(depths, depth)
+			lock.Unlock()
+		}
+	}
+	res := Solve(context.Background(), cube, SolveOptions{
+		Tables:   sharedSolverTables(HTM),
+		Observer: observer,
+	})
+	if res.Reason != StopExhausted || len(res.Solution) != 6 {
+		t.Fatalf("unexpected result: %v", res)
+	}
+
+	for i, depth := range depths {
+		if depth != i {
+			t.Fatalf("unexpected phase-1 depths: %v", depths)
+		}
+	}
+	stats := observer.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 phases but got %d", len(stats))
+	}
+	p1, p2 := stats[SearchPhase1], stats[SearchPhase2]
+	if p1.Depth != depths[len(depths)-1] {
+		t.Errorf("expected depth %d but got %d", depths[len(depths)-1],
+			p1.Depth)
+	}
+	if p1.Nodes == 0 || p1.Prunes == 0 || p1.Prunes > p1.Nodes {
+		t.Errorf("unexpected phase-1 stats: %+v", p1)
+	}
+	if p2.Searches == 0 || p2.Nodes < p2.Searches || p2.Prunes > p2.Nodes {
+		t.Errorf("unexpected phase-2 stats: %+v", p2)
+	}
+	if p1.Time <= 0 || p2.Time <= 0 {
+		t.Errorf("unexpected times: %v, %v", p1.Time, p2.Time)
+	}
+}
+
+func TestSearchObserverNil(t *testing.T) {
+	var observer *SearchObserver
+	phase := observer.Phase(SearchPhase2)
+	if phase != nil {
+		t.Fatal("expected nil phase")
+	}
+	phase.StartDepth(3)
+	phase.Node()
+	phase.Prune()
+	phase.EndSearch(phase.StartSearch())
+	if stats := phase.Stats(); stats != (PhaseStats{}) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
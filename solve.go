@@ -0,0 +1,136 @@
+package gocube
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// twoPhaseMaxLength is longer than any solution the two-phase search can
+// produce. In HTM, phase-1 takes at most 12 moves and phase-2 takes at most
+// 18, and a quarter turn solution is at most twice as long.
+const twoPhaseMaxLength = 60
+
+// SolveOptions configures Solve and NewSolverContext.
+type SolveOptions struct {
+	// Metric is the metric in which solutions are measured. Every length in
+	// the options uses this metric.
+	Metric Metric
+
+	// MoveCosts gives a cost for each Move, indexed by the Move, to use
+	// instead of the metric. Solutions are then measured by their total cost,
+	// and every length in the options is a cost. A cost of ForbiddenMove
+	// means that the move is never used.
+	//
+	// Costs must be positive or ForbiddenMove, and they can only be used with
+	// HTM. The solver generates phase-2 tables for the costs when it starts,
+	// which takes a moment and counts toward the TimeLimit. The tables are
+	// kept with the SolverTables, and solvers which use the same SolverTables
+	// and costs share them. The phase-1 heuristic is scaled by the cheapest cost, so widely
+	// varying costs make the search slower.
+	MoveCosts []int
+
+	// MaxLength is the length of the longest solution to report. If it is 0,
+	// solutions of any length are reported.
+	MaxLength int
+
+	// TargetLength stops the search once a solution of this length or shorter
+	// has been found. If it is 0, the search continues until no shorter
+	// solution can be found.
+	TargetLength int
+
+	// TimeLimit stops the search after a certain amount of time. If it is 0,
+	// there is no time limit.
+	TimeLimit time.Duration
+
+	// Tables are the tables to search with, which must use the same metric.
+	// If they are nil, tables are generated the first time they are needed
+	// and shared for the rest of the process.
+	Tables *SolverTables
+
+	// Observer, if it is non-nil, collects statistics about the search. See
+	// SearchPhase1 and SearchPhase2.
+	Observer *SearchObserver
+}
+
+// ForbiddenMove is the cost of a move which a solution may not use. See
+// SolveOptions.MoveCosts.
+const ForbiddenMove = -1
+
+// A StopReason indicates why a solver stopped.
+type StopReason int
+
+const (
+	// StopNone means that the solver has not stopped.
+	StopNone StopReason = iota
+
+	// StopExhausted means that the search could not find any more solutions
+	// within the maximum length.
+	StopExhausted
+
+	// StopTarget means that a solution reached the target length.
+	StopTarget
+
+	// StopTimeLimit means that the time limit ran out.
+	StopTimeLimit
+
+	// StopCanceled means that the context was done or the solver was stopped.
+	StopCanceled
+)
+
+// String returns a short description of the reason.
+func (s StopReason) String() string {
+	switch s {
+	case StopNone:
+		return "not stopped"
+	case StopExhausted:
+		return "search exhausted"
+	case StopTarget:
+		return "target length reached"
+	case StopTimeLimit:
+		return "time limit reached"
+	case StopCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// A SolveResult is the outcome of Solve.
+type SolveResult struct {
+	// Solution is the shortest solution which was found, or nil if none was
+	// found.
+	Solution []Move
+
+	// Reason is the reason that the search stopped.
+	Reason StopReason
+}
+
+// Solve searches for short solutions to a cube, blocking until the search
+// stops, and returns the shortest solution it found.
+//
+// Without a target length, the search only stops once it has exhausted every
+// solution length, which can take a very long time. Callers should usually set
+// TargetLength or TimeLimit, or use a context with a deadline.
+func Solve(ctx context.Context, c CubieCube, opts SolveOptions) SolveResult {
+	solver := NewSolverContext(ctx, c, opts)
+	var res SolveResult
+	for solution := range solver.Solutions() {
+		res.Solution = solution
+	}
+	res.Reason = solver.Reason()
+	return res
+}
+
+var sharedSolverTablesLock sync.Mutex
+var sharedSolverTablesData [metricCount]*SolverTables
+
+// sharedSolverTables returns the tables used when SolveOptions.Tables is nil.
+func sharedSolverTables(metric Metric) *SolverTables {
+	sharedSolverTablesLock.Lock()
+	defer sharedSolverTablesLock.Unlock()
+	if sharedSolverTablesData[metric] == nil {
+		sharedSolverTablesData[metric] = GenerateSolverTablesMetric(metric)
+	}
+	return sharedSolverTablesData[metric]
+}
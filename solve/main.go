@@ -1,27 +1,84 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/unixpickle/gocube"
 )
 
 func main() {
-	sc, err := gocube.InputStickerCube()
+	net := flag.Bool("net", false, "read the cube as an unfolded net")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: solve [-net | facelets]")
+		fmt.Fprintln(os.Stderr, "Without arguments, the stickers are entered "+
+			"face by face.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 || (*net && flag.NArg() > 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var sc *gocube.StickerCube
+	var err error
+	if *net {
+		fmt.Println("Paste an unfolded net:")
+		sc, err = gocube.ReadNet(os.Stdin)
+	} else if flag.NArg() > 0 {
+		sc, err = gocube.ParseFaceletString(flag.Arg(0))
+	} else {
+		sc, err = gocube.InputStickerCube()
+	}
 	if err != nil {
 		fmt.Println("Failed to read stickers:", err)
 		os.Exit(1)
 	}
-	cc, err := sc.CubieCube()
-	if err != nil {
+	if err := sc.Validate(); err != nil {
 		fmt.Println("Invalid stickers:", err)
 		os.Exit(1)
 	}
+	cc, _ := sc.CubieCube()
+
+	if info, err := os.Stdout.Stat(); err == nil &&
+		info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Print(sc.ANSINet())
+	} else {
+		fmt.Print(sc.Net())
+	}
+
+	fmt.Println("Loading tables...")
+	tables, err := gocube.LoadDefaultSolverTables()
+	if err != nil {
+		fmt.Println("Failed to cache tables:", err)
+	}
 
 	fmt.Println("Solving...")
-	solver := gocube.NewSolver(*cc, 30)
+	observer := &gocube.SearchObserver{}
+	observer.OnDepth = func(phase, depth int) {
+		if phase == gocube.SearchPhase1 && depth > 0 {
+			printProgress(observer.Stats())
+		}
+	}
+	solver := gocube.NewSolverContext(context.Background(), *cc,
+		gocube.SolveOptions{
+			MaxLength: 30,
+			Tables:    tables,
+			Observer:  observer,
+		})
 	for solution := range solver.Solutions() {
-		fmt.Println("Solution:", solution, "-", len(solution), "moves")
+		alg := gocube.NewAlgorithm(solution)
+		fmt.Println("Solution:", alg, "-", alg.HTM(), "moves")
 	}
 }
+
+func printProgress(stats []gocube.PhaseStats) {
+	p1, p2 := stats[gocube.SearchPhase1], stats[gocube.SearchPhase2]
+	fmt.Printf("Searching phase-1 depth %d (%d+%d nodes, %d phase-2 "+
+		"searches, %v+%v)\n", p1.Depth, p1.Nodes, p2.Nodes, p2.Searches,
+		p1.Time.Round(time.Millisecond), p2.Time.Round(time.Millisecond))
+}
@@ -0,0 +1,261 @@
+package gocube
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSolve(t *testing.T) {
+	tables := sharedSolverTables(HTM)
+	checkSolution := func(t *testing.T, cube CubieCube, solution []Move) {
+		for _, m := range solution {
+			cube.Move(m)
+		}
+		if !cube.Solved() {
+			t.Errorf("solution %v does not work", solution)
+		}
+	}
+
+	t.Run("Target", func(t *testing.T) {
+		cube := RandomCubieCube()
+		res := Solve(context.Background(), cube, SolveOptions{
+			TargetLength: 23,
+			Tables:       tables,
+		})
+		if res.Reason != StopTarget {
+			t.Errorf("unexpected reason: %s", res.Reason)
+		}
+		if len(res.Solution) > 23 {
+			t.Errorf("solution is too long: %v", res.Solution)
+		}
+		checkSolution(t, cube, res.Solution)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		cube := SolvedCubieCube()
+		moves, _ := ParseMoves("F R' D2 L")
+		for _, m := range moves {
+			cube.Move(m)
+		}
+		res := Solve(context.Background(), cube, SolveOptions{Tables: tables})
+		if res.Reason != StopExhausted {
+			t.Errorf("unexpected reason: %s", res.Reason)
+		}
+		if len(res.Solution) != 4 {
+			t.Errorf("unexpected solution: %v", res.Solution)
+		}
+		checkSolution(t, cube, res.Solution)
+
+		res = Solve(context.Background(), cube, SolveOptions{
+			MaxLength: 3,
+			Tables:    tables,
+		})
+		if res.Reason != StopExhausted || res.Solution != nil {
+			t.Errorf("unexpected result: %v", res)
+		}
+	})
+
+	t.Run("TimeLimit", func(t *testing.T) {
+		cube := RandomCubieCube()
+		start := time.Now()
+		res := Solve(context.Background(), cube, SolveOptions{
+			TimeLimit: time.Second / 4,
+			Tables:    tables,
+		})
+		if res.Reason != StopTimeLimit {
+			t.Errorf("unexpected reason: %s", res.Reason)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second*2 {
+			t.Errorf("search took %v", elapsed)
+		}
+		if res.Solution != nil {
+			checkSolution(t, cube, res.Solution)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		res := Solve(ctx, RandomCubieCube(), SolveOptions{Tables: tables})
+		if res.Reason != StopCanceled {
+			t.Errorf("unexpected reason: %s", res.Reason)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cube := SolvedCubieCube()
+		cube.Corners[0].Orientation = 0
+		res := Solve(context.Background(), cube, SolveOptions{Tables: tables})
+		if res.Reason != StopExhausted || res.Solution != nil {
+			t.Errorf("unexpected result: %v", res)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		solver := NewSolverContext(ctx, RandomCubieCube(),
+			SolveOptions{Tables: tables})
+		if _, ok := <-solver.Solutions(); !ok {
+			t.Fatal("no solution was found")
+		}
+		cancel()
+		for range solver.Solutions() {
+		}
+		if solver.Reason() != StopCanceled {
+			t.Errorf("unexpected reason: %s", solver.Reason())
+		}
+	})
+}
+
+func TestSolverStopTwice(t *testing.T) {
+	tables := sharedSolverTables(HTM)
+	cube := RandomCubieCube()
+
+	solver := NewSolverTables(cube, 30, *tables)
+	<-solver.Solutions()
+	solver.Stop()
+	solver.Stop()
+	for range solver.Solutions() {
+	}
+	if solver.Reason() != StopCanceled {
+		t.Errorf("unexpected reason: %s", solver.Reason())
+	}
+
+	phase1 := NewPhase1Solver(cube.Phase1Cube(), tables.P1Heuristic,
+		tables.P1Moves)
+	<-phase1.Solutions()
+	phase1.Stop()
+	phase1.Stop()
+
+	optimal := NewOptimalSolver(cube, &OptimalTables{})
+	optimal.Stop()
+	optimal.Stop()
+}
+
+func TestSolveMoveCosts(t *testing.T) {
+	tables := sharedSolverTables(HTM)
+	faceCosts := func(face, cost int) []int {
+		res := make([]int, 18)
+		for m := range res {
+			res[m] = 1
+			if Move(m).Face() == face {
+				res[m] = cost
+			}
+		}
+		return res
+	}
+	totalCost := func(costs []int, moves []Move) int {
+		var res int
+		for _, m := range moves {
+			if costs[m] < 0 {
+				t.Fatalf("solution uses a forbidden move: %v", moves)
+			}
+			res += costs[m]
+		}
+		return res
+	}
+
+	t.Run("Forbidden", func(t *testing.T) {
+		costs := faceCosts(4, ForbiddenMove)
+		cube := RandomCubieCube()
+		res := Solve(context.Background(), cube, SolveOptions{
+			MoveCosts:    costs,
+			TargetLength: 24,
+			Tables:       tables,
+		})
+		if res.Solution == nil {
+			t.Fatal("no solution was found")
+		}
+		totalCost(costs, res.Solution)
+		for _, m := range res.Solution {
+			cube.Move(m)
+		}
+		if !cube.Solved() {
+			t.Errorf("solution %v does not work", res.Solution)
+		}
+	})
+
+	t.Run("Ordered", func(t *testing.T) {
+		costs := faceCosts(2, 3)
+		solver := NewSolverContext(context.Background(), RandomCubieCube(),
+			SolveOptions{
+				MoveCosts:    costs,
+				TargetLength: 24,
+				Tables:       tables,
+			})
+		last := -1
+		for solution := range solver.Solutions() {
+			cost := totalCost(costs, solution)
+			if last >= 0 && cost >= last {
+				t.Errorf("cost %d came after %d", cost, last)
+			}
+			last = cost
+		}
+		if last < 0 {
+			t.Error("no solution was found")
+		}
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		costs := faceCosts(2, 2)
+		cube := SolvedCubieCube()
+		moves, _ := ParseMoves("F R' D2 L")
+		for _, m := range moves {
+			cube.Move(m)
+		}
+		res := Solve(context.Background(), cube, SolveOptions{
+			MoveCosts: costs,
+			Tables:    tables,
+		})
+		if res.Reason != StopExhausted {
+			t.Errorf("unexpected reason: %s", res.Reason)
+		}
+		if totalCost(costs, res.Solution) != 5 {
+			t.Errorf("unexpected solution: %v", res.Solution)
+		}
+	})
+
+	t.Run("Unsolvable", func(t *testing.T) {
+		costs := faceCosts(4, ForbiddenMove)
+		for m := range costs {
+			if Move(m).Face() == 2 {
+				costs[m] = ForbiddenMove
+			}
+		}
+
+		// Without B or D, the DB edge can never move.
+		cube := SolvedCubieCube()
+		cube.Move(NewMove(4, 1))
+		res := Solve(context.Background(), cube, SolveOptions{
+			MoveCosts: costs,
+			Tables:    tables,
+		})
+		if res.Reason != StopExhausted || res.Solution != nil {
+			t.Errorf("unexpected result: %v", res)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		// The tables for these costs are never built, since the context is
+		// done before the search starts.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cache := tables.phase2CostTables()
+		cache.lock.Lock()
+		built := len(cache.entries)
+		cache.lock.Unlock()
+		res := Solve(ctx, RandomCubieCube(), SolveOptions{
+			MoveCosts: faceCosts(1, 5),
+			Tables:    tables,
+		})
+		if res.Reason != StopCanceled || res.Solution != nil {
+			t.Errorf("unexpected result: %v", res)
+		}
+		cache.lock.Lock()
+		defer cache.lock.Unlock()
+		if len(cache.entries) != built {
+			t.Error("tables were built for a canceled solver")
+		}
+	})
+}
@@ -1,116 +1,541 @@
 package gocube
 
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // A Solver finds shorter and shorter solutions in the background.
+//
+// Since a Phase1Cube tracks all three axes, each phase-1 search covers the
+// three axis-conjugated versions of a state. The solver searches both the
+// state and its inverse this way, for six searches in total. A solution to the
+// inverse is inverted before it is delivered.
+//
+// The phase-1 search is split between a pool of workers, one per CPU, and each
+// worker runs the phase-2 searches for the phase-1 solutions it finds. The
+// workers share the length of the best solution so far, so that a solution
+// found by one worker prunes the search of every other worker.
 type Solver struct {
-	stopper   chan struct{}
+	// parent is the context passed to NewSolverContext, limited applies the
+	// time limit to it, and ctx is canceled to stop the search.
+	parent  context.Context
+	limited context.Context
+	ctx     context.Context
+	cancel  context.CancelFunc
+
 	solutions chan []Move
-	phase1    *Phase1Solver
+
+	// maxLength is the length of the longest solution which would still be an
+	// improvement. It is accessed atomically.
+	maxLength int32
+
+	targetLength int
+	targetHit    bool
+
+	// reason stores a StopReason. It is accessed atomically.
+	reason int32
+
+	// sendLock ensures that solutions are delivered in order of length.
+	sendLock sync.Mutex
+
+	// sides records the solverTask.side of each delivered solution, so that
+	// tests can check which search found it. It is guarded by sendLock.
+	sides []int
+
+	cube    CubieCube
+	metric  Metric
+	p2Moves *Phase2Moves
+
+	// p2CostTables stores the phase-2 bounds for the costs, which are built
+	// by the search goroutine.
+	p2CostTables *phase2CostCache
+
+	// costs stores the cost of each move for the original cube and for its
+	// inverse, or nil if moves are counted in the metric.
+	costs [2][]int
+
+	// unsolvable is set if the cube is invalid or the costs forbid every
+	// solution.
+	unsolvable bool
+
+	// phase1, p2MoveSets and p2Bounds are indexed by solverTask.side(), and
+	// the phase-2 fields are also indexed by the axis of phase-2.
+	phase1     [2]*Phase1Solver
+	p2MoveSets [2][3]*moveSet
+	p2Bounds   [2][3]phase2Bound
+
+	// The observers are nil if the search is not observed.
+	p1Observer *PhaseObserver
+	p2Observer *PhaseObserver
 }
 
 // NewSolver creates a new solver.
 func NewSolver(c CubieCube, max int) *Solver {
 	p1Moves := NewPhase1Moves()
-	p1Heuristic := NewPhase1Heuristic(p1Moves)
-
-	res := new(Solver)
-	res.stopper = make(chan struct{})
-	res.solutions = make(chan []Move)
-	res.phase1 = NewPhase1Solver(c.Phase1Cube(), p1Heuristic, p1Moves)
-
-	go res.backgroundLoop(c, nil, max)
-	return res
+	p2Moves := NewPhase2Moves()
+	return NewSolverTables(c, max, SolverTables{
+		P1Heuristic: NewPhase1Heuristic(p1Moves),
+		P1Moves:     p1Moves,
+		P2Heuristic: NewPhase2Heuristic(p2Moves),
+		P2Moves:     p2Moves,
+	})
 }
 
 // NewSolverTables creates a new solver using a set of pre-generated tables.
+// The solver uses the metric of the tables.
 func NewSolverTables(c CubieCube, max int, tables SolverTables) *Solver {
-	res := new(Solver)
-	res.stopper = make(chan struct{})
-	res.solutions = make(chan []Move)
-	res.phase1 = NewPhase1Solver(c.Phase1Cube(), tables.P1Heuristic,
-		tables.P1Moves)
+	return NewSolverContext(context.Background(), c, SolveOptions{
+		MaxLength: max,
+		Metric:    tables.Metric,
+		Tables:    &tables,
+	})
+}
+
+// NewSolverContext creates and starts a solver which is configured by opts.
+//
+// The solver stops when ctx is done, as well as for any of the reasons given
+// by the options. Either way, the Solutions channel is closed and Reason
+// reports why. An invalid cube has no solutions, so the search is exhausted
+// right away.
+//
+// This panics if opts.Tables were generated for a different metric than
+// opts.Metric, or if opts.MoveCosts are invalid.
+func NewSolverContext(ctx context.Context, c CubieCube,
+	opts SolveOptions) *Solver {
+	tables := opts.Tables
+	if tables == nil {
+		tables = sharedSolverTables(opts.Metric)
+	} else if tables.Metric != opts.Metric {
+		panic("solver tables use " + tables.Metric.String() + ", not " +
+			opts.Metric.String())
+	}
+	maxCost := 1
+	if opts.MoveCosts != nil {
+		if len(opts.MoveCosts) != 18 {
+			panic("there must be a cost for each of the 18 moves")
+		} else if opts.Metric != HTM {
+			panic("move costs can only be used with HTM")
+		}
+		for _, cost := range opts.MoveCosts {
+			if cost == 0 {
+				panic("move costs must be positive or ForbiddenMove")
+			} else if cost > maxCost {
+				maxCost = cost
+			}
+		}
+	}
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = twoPhaseMaxLength * maxCost
+	}
+
+	res := &Solver{
+		parent:       ctx,
+		limited:      ctx,
+		solutions:    make(chan []Move),
+		maxLength:    int32(maxLength),
+		targetLength: opts.TargetLength,
+		cube:         c,
+		unsolvable:   c.Validate() != nil,
+		metric:       opts.Metric,
+		p2Moves:      tables.P2Moves,
+		p1Observer:   opts.Observer.Phase(SearchPhase1),
+		p2Observer:   opts.Observer.Phase(SearchPhase2),
+	}
+	var cancelLimit context.CancelFunc
+	if opts.TimeLimit > 0 {
+		res.limited, cancelLimit = context.WithTimeout(ctx, opts.TimeLimit)
+	}
+	var cancel context.CancelFunc
+	res.ctx, cancel = context.WithCancel(res.limited)
+	res.cancel = func() {
+		cancel()
+		if cancelLimit != nil {
+			cancelLimit()
+		}
+	}
+
+	// A solution to the inverse is inverted, which inverts each of its moves,
+	// so the inverse is searched with the costs of the inverted moves.
+	if opts.MoveCosts != nil {
+		res.costs[0] = append([]int{}, opts.MoveCosts...)
+		res.costs[1] = make([]int, 18)
+		for m := range res.costs[1] {
+			res.costs[1][m] = opts.MoveCosts[Move(m).Inverse()]
+		}
+		if !allowedMovesSolve(c, opts.MoveCosts) {
+			res.unsolvable = true
+		}
+		res.p2CostTables = tables.phase2CostTables()
+	}
 
-	go res.backgroundLoop(c, &tables, max)
+	// The phase-1 solvers are never started; the workers call their
+	// depthFirst methods directly. The phase-2 bounds for weighted moves are
+	// filled in by the search goroutine, since they take a while to build.
+	for side, costs := range res.costs {
+		p1Set := phase1MoveSet(opts.Metric)
+		if costs != nil {
+			p1Set = p1Set.weighted(costs)
+		}
+		res.phase1[side] = &Phase1Solver{
+			ctx:       res.ctx,
+			cancel:    res.cancel,
+			heuristic: tables.P1Heuristic,
+			moves:     tables.P1Moves,
+			moveSet:   p1Set,
+			observer:  res.p1Observer,
+		}
+		for axis := range res.p2MoveSets[side] {
+			p2Set := phase2MoveSet(opts.Metric, axis)
+			if costs == nil {
+				res.p2Bounds[side][axis] = tables.P2Heuristic
+			} else {
+				p2Set = p2Set.weighted(costs)
+			}
+			res.p2MoveSets[side][axis] = p2Set
+		}
+	}
+
+	go res.search(runtime.GOMAXPROCS(0))
 	return res
 }
 
 // Solutions is a channel over which shorter and shorter solutions are
-// delivered.
+// delivered. It is closed when the solver stops.
 func (s *Solver) Solutions() <-chan []Move {
 	return s.solutions
 }
 
-// Stop stops the solver.
+// Stop stops the solver. It may be called more than once.
 func (s *Solver) Stop() {
-	s.phase1.Stop()
-	close(s.stopper)
-}
-
-func (s *Solver) backgroundLoop(c CubieCube, tables *SolverTables, max int) {
-	// Get the tables.
-	var p2Moves *Phase2Moves
-	var p2Heuristic *Phase2Heuristic
-	if tables != nil {
-		p2Moves = tables.P2Moves
-		p2Heuristic = tables.P2Heuristic
-	} else {
-		p2Moves = NewPhase2Moves()
-		p2Heuristic = NewPhase2Heuristic(p2Moves, false)
-	}
-
-OuterLoop:
-	for p1Solution := range s.phase1.Solutions() {
-		if len(p1Solution.Moves) > max {
-			s.Stop()
-			break
-		}
-
-		// Generate the cube after solving phase1.
-		cube := c
-		for _, m := range p1Solution.Moves {
-			cube.Move(m)
-		}
-		// The phase-1 solution could be in the x, y, or z axis. We will go
-		// through each axis and try solving it.
-		x, y, z := p1Solution.Cube.Solved()
-		for axis, solved := range []bool{x, y, z} {
-			if !solved {
-				continue
-			}
+	s.cancel()
+}
 
-			// Create the phase-2 cube and solve it.
-			cube, err := NewPhase2Cube(cube, axis)
-			if err != nil {
-				continue
-			}
-			p2Solution := SolvePhase2(cube, max-len(p1Solution.Moves),
-				p2Heuristic, p2Moves)
-			if p2Solution == nil {
-				continue
+// Reason returns the reason that the solver stopped. It returns StopNone until
+// the Solutions channel has been closed.
+func (s *Solver) Reason() StopReason {
+	return StopReason(atomic.LoadInt32(&s.reason))
+}
+
+// search runs an iterative deepening search for phase-1 solutions. Each depth
+// is divided into tasks by the starting state and the first moves of the
+// solutions, and the tasks are run by a pool of workers.
+func (s *Solver) search(workers int) {
+	defer func() {
+		atomic.StoreInt32(&s.reason, int32(s.stopReason()))
+		s.cancel()
+		close(s.solutions)
+	}()
+
+	if s.unsolvable || !s.buildCostTables() {
+		return
+	}
+
+	starts := []CubieCube{s.cube}
+	if inverse := s.cube.Inverse(); inverse != s.cube {
+		starts = append(starts, inverse)
+	}
+
+	for depth := 0; depth <= s.maxLen(); depth++ {
+		s.p1Observer.StartDepth(depth)
+		tasks := make(chan solverTask)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for task := range tasks {
+					s.searchTask(task, depth)
+				}
+			}()
+		}
+
+	TaskLoop:
+		for i, start := range starts {
+			for _, prefix := range s.phase1Prefixes(s.phase1[i].moveSet, depth) {
+				task := solverTask{start: start, inverse: i == 1, prefix: prefix}
+				select {
+				case <-s.ctx.Done():
+					break TaskLoop
+				case tasks <- task:
+				}
 			}
+		}
+		close(tasks)
+		wg.Wait()
 
-			// Join the two solutions.
-			solution := make([]Move, len(p1Solution.Moves))
-			copy(solution, p1Solution.Moves)
-			for _, move := range p2Solution {
-				solution = append(solution, move.Move(axis))
+		if s.isStopped() {
+			return
+		}
+	}
+}
+
+// buildCostTables fills in the phase-2 bounds for weighted moves. It returns
+// false if the solver was stopped first.
+func (s *Solver) buildCostTables() bool {
+	for side, costs := range s.costs {
+		if costs == nil {
+			continue
+		}
+		for axis, set := range s.p2MoveSets[side] {
+			table := s.p2CostTables.get(s.ctx, s.p2Moves, set, costs)
+			if table == nil {
+				return false
 			}
-			max = len(solution) - 1
-			select {
-			case <-s.stopper:
-				break OuterLoop
-			case s.solutions <- solution:
+			s.p2Bounds[side][axis] = table
+		}
+	}
+	return true
+}
+
+// A solverTask is a portion of the phase-1 search: the solutions to one of the
+// starting states which begin with certain moves.
+type solverTask struct {
+	start   CubieCube
+	inverse bool
+
+	// prefix lists indices into the phase-1 moveSet.
+	prefix []int
+}
+
+// side returns 0 for a task on the original cube and 1 for its inverse.
+func (t *solverTask) side() int {
+	if t.inverse {
+		return 1
+	}
+	return 0
+}
+
+// searchTask searches for phase-1 solutions of a given length and solves
+// phase-2 for each of them.
+func (s *Solver) searchTask(task solverTask, depth int) {
+	// The phase-2 searches are timed separately.
+	start := s.p1Observer.StartSearch()
+	var p2Time time.Duration
+	defer func() {
+		s.p1Observer.EndSearch(start)
+		s.p1Observer.AddTime(-p2Time)
+	}()
+
+	var moves []Move
+	phase1 := s.phase1[task.side()]
+	cube := task.start.Phase1Cube()
+	last := -1
+	for _, i := range task.prefix {
+		searchMove := phase1.moveSet.moves[i]
+		for _, m := range searchMove.moves {
+			cube.Move(Move(m), phase1.moves)
+			moves = append(moves, Move(m))
+		}
+		depth -= searchMove.cost
+		last = i
+	}
+	phase1.depthFirst(cube, moves, depth, last,
+		func(p1Solution Phase1Solution) bool {
+			if s.p1Observer == nil {
+				return s.solvePhase2(task, p1Solution)
 			}
-			if len(p2Solution) == 0 {
-				break OuterLoop
+			p2Start := time.Now()
+			defer func() {
+				p2Time += time.Since(p2Start)
+			}()
+			return s.solvePhase2(task, p1Solution)
+		})
+}
+
+// solvePhase2 finishes a phase-1 solution and delivers the result if it is an
+// improvement. It returns false if the solver was stopped.
+func (s *Solver) solvePhase2(task solverTask, p1Solution Phase1Solution) bool {
+	side := task.side()
+	p1Length := s.length(side, p1Solution.Moves)
+	if p1Length > s.maxLen() {
+		return true
+	}
+
+	// Generate the cube after solving phase1.
+	cube := task.start
+	for _, m := range p1Solution.Moves {
+		cube.Move(m)
+	}
+
+	// The phase-1 solution could be in the x, y, or z axis. We will go
+	// through each axis and try solving it.
+	x, y, z := p1Solution.Cube.Solved()
+	for axis, solved := range []bool{x, y, z} {
+		if !solved {
+			continue
+		}
+
+		// Create the phase-2 cube and solve it. The solver only searches
+		// valid cubes, and the phase-1 solution reduces it on this axis.
+		p2Cube := encodePhase2Cube(&cube, axis)
+		p2Solution := solvePhase2(s.ctx, p2Cube, s.maxLen()-p1Length,
+			s.p2Bounds[side][axis], s.p2Moves, s.p2MoveSets[side][axis],
+			s.p2Observer)
+		if p2Solution == nil {
+			continue
+		}
+
+		// Join the two solutions, cancelling moves at the seam.
+		joined := make([]Move, len(p1Solution.Moves))
+		copy(joined, p1Solution.Moves)
+		for _, move := range p2Solution {
+			joined = append(joined, move.Move(axis))
+		}
+		// Moves which are merged at the seam may cost more than the moves
+		// they replace, or they may be forbidden.
+		solution, _ := NewAlgorithm(joined).Simplify().Moves()
+		if s.length(side, solution) > s.length(side, joined) {
+			solution = joined
+		}
+		if task.inverse {
+			// A solution to the inverse, when inverted, solves the original.
+			solution, _ = NewAlgorithm(solution).Inverse().Moves()
+		}
+		if !s.deliver(solution, side) {
+			return false
+		}
+	}
+	return true
+}
+
+// deliver sends a solution if it is shorter than every solution so far, and
+// lowers the shared bound accordingly. It returns false if the solver was
+// stopped.
+func (s *Solver) deliver(solution []Move, side int) bool {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	length := s.length(0, solution)
+	if length > s.maxLen() {
+		return true
+	}
+	if solution == nil {
+		solution = []Move{}
+	}
+	atomic.StoreInt32(&s.maxLength, int32(length-1))
+	select {
+	case <-s.ctx.Done():
+		return false
+	case s.solutions <- solution:
+	}
+	s.sides = append(s.sides, side)
+	if length <= s.targetLength {
+		s.targetHit = true
+		s.cancel()
+		return false
+	}
+	return true
+}
+
+// stopReason determines why the search ended. It must be called by the search
+// goroutine once the workers are done.
+func (s *Solver) stopReason() StopReason {
+	if s.targetHit {
+		return StopTarget
+	} else if s.ctx.Err() == nil {
+		return StopExhausted
+	} else if s.parent.Err() == nil && s.limited.Err() != nil {
+		return StopTimeLimit
+	}
+	return StopCanceled
+}
+
+func (s *Solver) maxLen() int {
+	return int(atomic.LoadInt32(&s.maxLength))
+}
+
+func (s *Solver) isStopped() bool {
+	return s.ctx.Err() != nil
+}
+
+// allowedMovesSolve checks if a cube can be solved without the moves which
+// have negative costs.
+func allowedMovesSolve(c CubieCube, costs []int) bool {
+	var gens []stickerPerm
+	for m, cost := range costs {
+		if cost >= 0 {
+			gens = append(gens, turnStickerPerm(MoveTurn(Move(m))))
+		}
+	}
+	cube := OrientedCube{Cube: c, Orientation: StandardOrientation()}
+	return newPermGroup(gens).contains(stickerPermutation(&cube))
+}
+
+// length measures a sequence of moves for one side of the search, using the
+// move costs if there are any. A sequence with a forbidden move has a length
+// longer than any solution.
+func (s *Solver) length(side int, moves []Move) int {
+	costs := s.costs[side]
+	if costs == nil {
+		return s.metric.Length(moves)
+	}
+	var res int
+	for _, m := range moves {
+		if costs[m] < 0 {
+			return math.MaxInt32
+		}
+		res += costs[m]
+	}
+	return res
+}
+
+// phase1Prefixes divides the phase-1 search at a given depth into tasks. It
+// returns the first two search moves of every solution, or the entire solution
+// if it has fewer than two search moves.
+func (s *Solver) phase1Prefixes(set *moveSet, depth int) [][]int {
+	var res [][]int
+	var extend func(prefix []int, cost int)
+	extend = func(prefix []int, cost int) {
+		if len(prefix) == 2 || cost == depth {
+			res = append(res, prefix)
+			return
+		}
+		last := -1
+		if len(prefix) > 0 {
+			last = prefix[len(prefix)-1]
+		}
+		for i, m := range set.moves {
+			if set.allowed[last+1][i] && cost+m.cost <= depth {
+				extend(append(append([]int{}, prefix...), i), cost+m.cost)
 			}
 		}
 	}
-	close(s.solutions)
+	extend(nil, 0)
+	return res
 }
 
+// SolverTables stores the tables used by a Solver, so that they can be shared
+// between solvers. See GenerateSolverTables and LoadOrGenerateSolverTables.
 type SolverTables struct {
+	// Metric is the metric which the heuristics use.
+	Metric Metric
+
 	P1Heuristic *Phase1Heuristic
 	P1Moves     *Phase1Moves
 	P2Heuristic *Phase2Heuristic
 	P2Moves     *Phase2Moves
+
+	// costTables is created by phase2CostTables.
+	costTables *phase2CostCache
+}
+
+var solverTablesCostLock sync.Mutex
+
+// phase2CostTables returns the phase-2 tables which solvers have built for
+// SolveOptions.MoveCosts with these tables. They are kept for as long as the
+// SolverTables are.
+func (s *SolverTables) phase2CostTables() *phase2CostCache {
+	solverTablesCostLock.Lock()
+	defer solverTablesCostLock.Unlock()
+	if s.costTables == nil {
+		s.costTables = &phase2CostCache{
+			entries: map[[10]int]*phase2CostEntry{},
+		}
+	}
+	return s.costTables
 }
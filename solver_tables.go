@@ -0,0 +1,313 @@
+package gocube
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// solverTablesVersion must be changed whenever the format or the contents of
+// the tables change, so that old files are regenerated.
+const solverTablesVersion = 4
+
+const solverTablesHeaderSize = 24
+
+var solverTablesMagic = []byte("gocubeST")
+
+// These errors are returned when a file does not contain usable SolverTables.
+var (
+	ErrSolverTablesCorrupt = errors.New("solver tables are corrupt")
+	ErrSolverTablesVersion = errors.New("solver tables have an old version")
+)
+
+var solverTablesCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// The metric is stored first, as a 32-bit integer. It is followed by the
+// heuristics, as raw bytes, so that a mapped file can be used without copying
+// them.
+const (
+	flipSliceTwistSize = (flipSliceClassCount*2187 + 1) / 2
+	cornersSliceSize   = cornerPermClassCount * 24
+	edgesSliceSize     = edgePermClassCount * 24
+
+	metricSize        = 4
+	p1HeuristicOffset = metricSize
+	p1HeuristicSize   = flipSliceTwistSize
+	p2HeuristicOffset = p1HeuristicOffset + p1HeuristicSize
+	p2HeuristicSize   = cornersSliceSize + edgesSliceSize
+	solverMovesOffset = p2HeuristicOffset + p2HeuristicSize
+
+	// The move tables are stored as 16-bit integers.
+	solverMovesSize = (495+2048+2187)*18*2 + (40320*2+24)*10*2
+
+	solverTablesPayloadSize = solverMovesOffset + solverMovesSize
+)
+
+// GenerateSolverTables generates all of the tables used by a Solver in the
+// half turn metric.
+//
+// This takes several seconds, so programs which solve many cubes over separate
+// runs should use LoadOrGenerateSolverTables to keep the tables in a file.
+func GenerateSolverTables() *SolverTables {
+	return GenerateSolverTablesMetric(HTM)
+}
+
+// GenerateSolverTablesMetric generates all of the tables used by a Solver in
+// the given metric.
+func GenerateSolverTablesMetric(metric Metric) *SolverTables {
+	p1Moves := NewPhase1Moves()
+	p2Moves := NewPhase2Moves()
+	return &SolverTables{
+		Metric:      metric,
+		P1Heuristic: NewPhase1HeuristicMetric(p1Moves, metric),
+		P1Moves:     p1Moves,
+		P2Heuristic: NewPhase2HeuristicMetric(p2Moves, metric),
+		P2Moves:     p2Moves,
+	}
+}
+
+// WriteTo encodes the tables in a versioned binary format which can be read by
+// ReadSolverTables.
+func (s *SolverTables) WriteTo(w io.Writer) (int64, error) {
+	payload := make([]byte, metricSize, solverTablesPayloadSize)
+	binary.LittleEndian.PutUint32(payload, uint32(s.Metric))
+	payload = append(payload, s.P1Heuristic.FlipSliceTwist...)
+	payload = append(payload, s.P2Heuristic.CornersSlice...)
+	payload = append(payload, s.P2Heuristic.EdgesSlice...)
+
+	var buf [2]byte
+	put := func(x int) {
+		binary.LittleEndian.PutUint16(buf[:], uint16(x))
+		payload = append(payload, buf[:]...)
+	}
+	for _, row := range s.P1Moves.ESliceMoves {
+		for _, x := range row {
+			put(x)
+		}
+	}
+	for _, row := range s.P1Moves.EOMoves {
+		for _, x := range row {
+			put(x)
+		}
+	}
+	for _, row := range s.P1Moves.COMoves {
+		for _, x := range row {
+			put(x)
+		}
+	}
+	for _, table := range [][][10]int{s.P2Moves.CornerMoves[:],
+		s.P2Moves.EdgeMoves[:], s.P2Moves.SliceMoves[:]} {
+		for _, row := range table {
+			for _, x := range row {
+				put(x)
+			}
+		}
+	}
+
+	header := make([]byte, solverTablesHeaderSize)
+	copy(header, solverTablesMagic)
+	binary.LittleEndian.PutUint32(header[8:], solverTablesVersion)
+	binary.LittleEndian.PutUint32(header[12:],
+		crc32.Checksum(payload, solverTablesCRC))
+	binary.LittleEndian.PutUint64(header[16:], uint64(len(payload)))
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(payload)
+	return int64(n + m), err
+}
+
+// ReadSolverTables reads tables which were written by SolverTables.WriteTo.
+//
+// It returns ErrSolverTablesVersion if the tables were written by a different
+// version of this package, and ErrSolverTablesCorrupt if the data is damaged.
+func ReadSolverTables(r io.Reader) (*SolverTables, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := solverTablesPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	res := new(SolverTables)
+	res.Metric = Metric(binary.LittleEndian.Uint32(payload))
+	res.P1Heuristic, res.P2Heuristic = decodeSolverHeuristics(
+		payload[p1HeuristicOffset:solverMovesOffset])
+	res.P1Moves, res.P2Moves = decodeSolverMoves(payload[solverMovesOffset:])
+	return res, nil
+}
+
+// LoadSolverTables reads tables from a file. See ReadSolverTables for details.
+func LoadSolverTables(path string) (*SolverTables, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSolverTables(f)
+}
+
+// SaveSolverTables writes tables to a file.
+//
+// The tables are written to a temporary file which replaces the destination
+// once it is complete, so other processes never see a partial file.
+func SaveSolverTables(path string, s *SolverTables) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if _, err := s.WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// LoadOrGenerateSolverTables loads tables from a file. If the file is missing,
+// stale or corrupt, the tables are generated and saved to the file.
+//
+// The returned tables are never nil. If the file cannot be read for another
+// reason, or if the new tables cannot be saved, the generated tables are
+// returned along with the error.
+func LoadOrGenerateSolverTables(path string) (*SolverTables, error) {
+	res, err := LoadSolverTables(path)
+	if err == nil {
+		return res, nil
+	} else if !os.IsNotExist(err) && err != ErrSolverTablesVersion &&
+		err != ErrSolverTablesCorrupt {
+		return GenerateSolverTables(), err
+	}
+	res = GenerateSolverTables()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return res, err
+	}
+	return res, SaveSolverTables(path, res)
+}
+
+// DefaultSolverTablesPath returns the file in the user's cache directory which
+// the command-line tools use to store their tables.
+func DefaultSolverTablesPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gocube", "solver_tables"), nil
+}
+
+// LoadDefaultSolverTables is like LoadOrGenerateSolverTables, but it uses the
+// file given by DefaultSolverTablesPath. If there is no cache directory, the
+// tables are generated and returned along with the error.
+func LoadDefaultSolverTables() (*SolverTables, error) {
+	path, err := DefaultSolverTablesPath()
+	if err != nil {
+		return GenerateSolverTables(), err
+	}
+	return LoadOrGenerateSolverTables(path)
+}
+
+// MappedSolverTables are SolverTables whose heuristics are backed by a
+// read-only memory-mapped file, so that several processes can share them.
+//
+// The tables must not be used after Close is called.
+type MappedSolverTables struct {
+	SolverTables
+
+	data []byte
+}
+
+// solverTablesPayload checks the header and checksum of encoded tables and
+// returns the data which follows the header.
+func solverTablesPayload(data []byte) ([]byte, error) {
+	if len(data) < solverTablesHeaderSize ||
+		!bytes.Equal(data[:8], solverTablesMagic) {
+		return nil, ErrSolverTablesCorrupt
+	}
+	if binary.LittleEndian.Uint32(data[8:]) != solverTablesVersion {
+		return nil, ErrSolverTablesVersion
+	}
+	payload := data[solverTablesHeaderSize:]
+	if binary.LittleEndian.Uint64(data[16:]) != solverTablesPayloadSize ||
+		len(payload) != solverTablesPayloadSize ||
+		crc32.Checksum(payload, solverTablesCRC) !=
+			binary.LittleEndian.Uint32(data[12:]) ||
+		binary.LittleEndian.Uint32(payload) >= uint32(metricCount) {
+		return nil, ErrSolverTablesCorrupt
+	}
+	return payload, nil
+}
+
+// decodeSolverHeuristics creates heuristics whose tables refer to the payload
+// of encoded tables, without copying it.
+func decodeSolverHeuristics(payload []byte) (*Phase1Heuristic,
+	*Phase2Heuristic) {
+	next := func(size int) []uint8 {
+		res := payload[:size:size]
+		payload = payload[size:]
+		return res
+	}
+	p1 := &Phase1Heuristic{
+		FlipSliceTwist: next(flipSliceTwistSize),
+		sym:            phase1Sym(),
+	}
+	p2 := &Phase2Heuristic{
+		CornersSlice: next(cornersSliceSize),
+		EdgesSlice:   next(edgesSliceSize),
+		sym:          phase2Sym(),
+	}
+	return p1, p2
+}
+
+func decodeSolverMoves(data []byte) (*Phase1Moves, *Phase2Moves) {
+	next := func() int {
+		x := binary.LittleEndian.Uint16(data)
+		data = data[2:]
+		return int(x)
+	}
+	p1 := new(Phase1Moves)
+	for i := range p1.ESliceMoves {
+		for j := range p1.ESliceMoves[i] {
+			p1.ESliceMoves[i][j] = next()
+		}
+	}
+	for i := range p1.EOMoves {
+		for j := range p1.EOMoves[i] {
+			p1.EOMoves[i][j] = next()
+		}
+	}
+	for i := range p1.COMoves {
+		for j := range p1.COMoves[i] {
+			p1.COMoves[i][j] = next()
+		}
+	}
+	p2 := new(Phase2Moves)
+	for _, table := range [][][10]int{p2.CornerMoves[:], p2.EdgeMoves[:],
+		p2.SliceMoves[:]} {
+		for i := range table {
+			for j := range table[i] {
+				table[i][j] = next()
+			}
+		}
+	}
+	return p1, p2
+}
@@ -0,0 +1,61 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package gocube
+
+import (
+	"encoding/binary"
+	"os"
+	"syscall"
+)
+
+// MapSolverTables maps a file written by SaveSolverTables into memory.
+//
+// The heuristics are used directly from the read-only mapping, so processes
+// which map the same file share their pages. The move tables are copied.
+//
+// The checksum is verified, so the same errors as ReadSolverTables may be
+// returned.
+func MapSolverTables(path string) (*MappedSolverTables, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() != solverTablesHeaderSize+solverTablesPayloadSize {
+		return nil, ErrSolverTablesCorrupt
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()),
+		syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := solverTablesPayload(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+
+	res := &MappedSolverTables{data: data}
+	res.Metric = Metric(binary.LittleEndian.Uint32(payload))
+	res.P1Heuristic, res.P2Heuristic = decodeSolverHeuristics(
+		payload[p1HeuristicOffset:solverMovesOffset])
+	res.P1Moves, res.P2Moves = decodeSolverMoves(payload[solverMovesOffset:])
+	return res, nil
+}
+
+// Close unmaps the file.
+func (m *MappedSolverTables) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	m.P1Heuristic = nil
+	m.P2Heuristic = nil
+	return err
+}
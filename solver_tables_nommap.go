@@ -0,0 +1,23 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package gocube
+
+// MapSolverTables loads a file written by SaveSolverTables.
+//
+// Memory mapping is not supported on this platform, so the file is read into
+// memory like LoadSolverTables.
+func MapSolverTables(path string) (*MappedSolverTables, error) {
+	tables, err := LoadSolverTables(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MappedSolverTables{SolverTables: *tables}, nil
+}
+
+// Close releases the tables.
+func (m *MappedSolverTables) Close() error {
+	m.P1Heuristic = nil
+	m.P2Heuristic = nil
+	return nil
+}
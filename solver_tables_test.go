@@ -0,0 +1,137 @@
+package gocube
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSolverTables(t *testing.T) {
+	tables := sharedSolverTables(HTM)
+	var buf bytes.Buffer
+	if _, err := tables.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	t.Run("Read", func(t *testing.T) {
+		decoded, err := ReadSolverTables(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSolverTablesEqual(t, tables, decoded)
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		data := append([]byte{}, encoded...)
+		data[len(data)/2] ^= 1
+		if _, err := ReadSolverTables(bytes.NewReader(data)); err !=
+			ErrSolverTablesCorrupt {
+			t.Errorf("unexpected error for damaged data: %v", err)
+		}
+		if _, err := ReadSolverTables(bytes.NewReader(
+			encoded[:len(encoded)-1])); err != ErrSolverTablesCorrupt {
+			t.Errorf("unexpected error for truncated data: %v", err)
+		}
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		data := append([]byte{}, encoded...)
+		binary.LittleEndian.PutUint32(data[8:], solverTablesVersion+1)
+		if _, err := ReadSolverTables(bytes.NewReader(data)); err !=
+			ErrSolverTablesVersion {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	dir, err := os.MkdirTemp("", "gocube")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tables")
+	if err := SaveSolverTables(path, tables); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Map", func(t *testing.T) {
+		mapped, err := MapSolverTables(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSolverTablesEqual(t, tables, &mapped.SolverTables)
+		if err := mapped.Close(); err != nil {
+			t.Error(err)
+		}
+		if err := mapped.Close(); err != nil {
+			t.Error("second close failed:", err)
+		}
+	})
+
+	t.Run("LoadOrGenerate", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("regenerating the tables is slow")
+		}
+		loaded, err := LoadOrGenerateSolverTables(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSolverTablesEqual(t, tables, loaded)
+
+		// A damaged file is replaced with a new one.
+		if err := os.WriteFile(path, encoded[:100], 0644); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err = LoadOrGenerateSolverTables(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSolverTablesEqual(t, tables, loaded)
+		if _, err := LoadSolverTables(path); err != nil {
+			t.Error("file was not regenerated:", err)
+		}
+
+		// A missing directory is created.
+		newPath := filepath.Join(dir, "cache", "tables")
+		if _, err := LoadOrGenerateSolverTables(newPath); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(newPath); err != nil {
+			t.Error(err)
+		}
+
+		// Tables are generated even if the file cannot be read.
+		loaded, err = LoadOrGenerateSolverTables(dir)
+		if err == nil {
+			t.Error("expected an error for a directory")
+		}
+		if loaded == nil {
+			t.Fatal("no tables were returned")
+		}
+		checkSolverTablesEqual(t, tables, loaded)
+	})
+}
+
+func checkSolverTablesEqual(t *testing.T, expected, actual *SolverTables) {
+	if expected.Metric != actual.Metric {
+		t.Errorf("expected metric %s but got %s", expected.Metric, actual.Metric)
+	}
+	p1, p2 := expected.P1Heuristic, actual.P1Heuristic
+	if !bytes.Equal(p1.FlipSliceTwist, p2.FlipSliceTwist) {
+		t.Error("phase-1 heuristics differ")
+	}
+	if *expected.P1Moves != *actual.P1Moves {
+		t.Error("phase-1 moves differ")
+	}
+	if !bytes.Equal(expected.P2Heuristic.CornersSlice,
+		actual.P2Heuristic.CornersSlice) ||
+		!bytes.Equal(expected.P2Heuristic.EdgesSlice,
+			actual.P2Heuristic.EdgesSlice) {
+		t.Error("phase-2 heuristics differ")
+	}
+	if *expected.P2Moves != *actual.P2Moves {
+		t.Error("phase-2 moves differ")
+	}
+}
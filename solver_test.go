@@ -1,18 +1,12 @@
 package gocube
 
-import "testing"
+import (
+	"runtime"
+	"testing"
+)
 
 func TestSolverRandomCubes(t *testing.T) {
-	p1Moves := NewPhase1Moves()
-	p1Heuristic := NewPhase1Heuristic(p1Moves)
-	p2Moves := NewPhase2Moves()
-	p2Heuristic := NewPhase2Heuristic(p2Moves, false)
-	tables := SolverTables{
-		P1Heuristic: p1Heuristic,
-		P1Moves:     p1Moves,
-		P2Heuristic: p2Heuristic,
-		P2Moves:     p2Moves,
-	}
+	tables := *sharedSolverTables(HTM)
 	for i := 0; i < 20; i++ {
 		cube := RandomCubieCube()
 		solver := NewSolverTables(cube, 24, tables)
@@ -24,5 +18,74 @@ func TestSolverRandomCubes(t *testing.T) {
 		if !cube.Solved() {
 			t.Errorf("%d: solution %v resulted in cube %v", i, solution, cube)
 		}
+		if simple := NewAlgorithm(solution).Simplify(); len(simple) != len(solution) {
+			t.Errorf("%d: solution %v can be simplified to %v", i, solution,
+				simple)
+		}
+	}
+}
+
+func TestSolverWorkers(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	tables := sharedSolverTables(HTM)
+	scramble, _ := ParseMoves("R U2 F' L D2 B'")
+	cube := SolvedCubieCube()
+	for _, m := range scramble {
+		cube.Move(m)
+	}
+
+	// The solver finishes on its own once no shorter solution can be found.
+	solver := NewSolverTables(cube, 20, *tables)
+	var lengths []int
+	var last []Move
+	for solution := range solver.Solutions() {
+		if len(lengths) > 0 && len(solution) >= lengths[len(lengths)-1] {
+			t.Errorf("solution %v is not an improvement", solution)
+		}
+		lengths = append(lengths, len(solution))
+		last = solution
+	}
+	if len(last) == 0 || len(last) > len(scramble) {
+		t.Fatalf("unexpected final solution %v", last)
+	}
+	for _, m := range last {
+		cube.Move(m)
+	}
+	if !cube.Solved() {
+		t.Errorf("solution %v does not work", last)
+	}
+}
+
+func TestSolverInverse(t *testing.T) {
+	tables := sharedSolverTables(HTM)
+
+	// The inverse of this state is one move away from phase-2, so its first
+	// solution should come from the inverse search.
+	scramble, _ := ParseMoves("F U R2 D' B2 L2 U2 F2 D R2 U' B2 D2")
+	cube := SolvedCubieCube()
+	for _, m := range scramble {
+		cube.Move(m)
+	}
+	solver := NewSolverTables(cube, 30, *tables)
+	solution := <-solver.Solutions()
+	solver.Stop()
+	for range solver.Solutions() {
+	}
+	solver.sendLock.Lock()
+	if len(solver.sides) == 0 || solver.sides[0] != 1 {
+		t.Errorf("first solution came from side %v, not the inverse",
+			solver.sides)
+	}
+	solver.sendLock.Unlock()
+
+	if len(solution) > len(scramble) {
+		t.Errorf("solution %v is longer than the scramble", solution)
+	}
+	for _, m := range solution {
+		cube.Move(m)
+	}
+	if !cube.Solved() {
+		t.Errorf("solution %v does not work", solution)
 	}
 }
@@ -250,21 +250,11 @@ func parseFace(str string, fill int) ([]int, error) {
 
 	// Read each character of their input.
 	for i, c := range runes {
-		if c == '1' || c == 'W' || c == 'w' {
-			res[i] = 1
-		} else if c == '2' || c == 'Y' || c == 'y' {
-			res[i] = 2
-		} else if c == '3' || c == 'G' || c == 'g' {
-			res[i] = 3
-		} else if c == '4' || c == 'B' || c == 'b' {
-			res[i] = 4
-		} else if c == '5' || c == 'R' || c == 'r' {
-			res[i] = 5
-		} else if c == '6' || c == 'O' || c == 'o' {
-			res[i] = 6
-		} else {
+		color := stickerColor(c)
+		if color == 0 {
 			return nil, errors.New("unexpected character: " + string(c))
 		}
+		res[i] = color
 	}
 
 	// If they left out the ending, we fill it in with the face number.
@@ -275,6 +265,26 @@ func parseFace(str string, fill int) ([]int, error) {
 	return res, nil
 }
 
+// stickerColor returns the color for a digit or color letter, or 0 if the
+// character does not name a color.
+func stickerColor(c rune) int {
+	switch c {
+	case '1', 'W', 'w':
+		return 1
+	case '2', 'Y', 'y':
+		return 2
+	case '3', 'G', 'g':
+		return 3
+	case '4', 'B', 'b':
+		return 4
+	case '5', 'R', 'r':
+		return 5
+	case '6', 'O', 'o':
+		return 6
+	}
+	return 0
+}
+
 func readFace(number int) ([]int, error) {
 	var line string
 	if _, err := fmt.Scanln(&line); err != nil {
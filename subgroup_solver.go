@@ -0,0 +1,540 @@
+package gocube
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+)
+
+// ErrNotInSubgroup is returned when a cube cannot be solved using a set of
+// generators.
+var ErrNotInSubgroup = errors.New("cube is not in the subgroup of the generators")
+
+// subgroupDatabaseSize is the largest number of entries in each of the
+// distance tables of a SubgroupTables.
+const subgroupDatabaseSize = 1 << 22
+
+// stickerPieces lists the sticker positions of every slot, grouped into
+// corners, edges and centers. The first sticker of each slot is its reference
+// sticker.
+var stickerPieces = generateStickerPieces()
+
+func generateStickerPieces() [3][][]int {
+	var res [3][][]int
+	for i := 0; i < 8; i++ {
+		res[0] = append(res[0], CornerIndexes[i*3:i*3+3])
+	}
+	for i := 0; i < 12; i++ {
+		res[1] = append(res[1], EdgeIndexes[i*2:i*2+2])
+	}
+	for i := 0; i < 6; i++ {
+		res[2] = append(res[2], []int{i*9 + 4})
+	}
+	return res
+}
+
+// SubgroupTables stores the tables for solving cubes with a restricted set of
+// generators, such as <R,U> or <M,U>.
+//
+// Unlike the other solvers, these tables track the centers, so the generators
+// may include slices and wide turns. A cube is only solved once every piece
+// and center is back in its original position.
+type SubgroupTables struct {
+	// Generators are the turns which generate the subgroup.
+	Generators Algorithm
+
+	group *permGroup
+
+	// moves lists every turn which a solution may use, along with the way
+	// that each turn permutes the stickers.
+	moves     []Turn
+	movePerms []stickerPerm
+
+	// follows indicates if a move may follow another. The first index is one
+	// more than the index of the previous move, or 0 at the start of a
+	// solution.
+	follows [][]bool
+
+	// pieces lists the reference sticker of every piece which the generators
+	// can move. Other pieces never leave their slots.
+	pieces []int
+
+	databases []*subgroupDatabase
+}
+
+// NewSubgroupTables generates the tables for the subgroup generated by a set
+// of turns.
+//
+// A generator which is a quarter turn allows every turn of its layer, while a
+// generator which is a half turn only allows the half turn. Rotations cannot
+// be used as generators.
+//
+// Small subgroups like <R,U> only take a moment. Larger ones, like <R,U,F>,
+// take several seconds and use up to 4MB per table.
+func NewSubgroupTables(generators Algorithm) (*SubgroupTables, error) {
+	res := &SubgroupTables{Generators: append(Algorithm{}, generators...)}
+	var genPerms []stickerPerm
+	for _, g := range generators {
+		if g.Kind == RotationTurn {
+			return nil, errors.New("rotation cannot be a generator: " + g.String())
+		}
+		genPerms = append(genPerms, turnStickerPerm(g))
+		turns := []int{2}
+		if g.Turns != 2 {
+			turns = []int{1, -1, 2}
+		}
+		for _, t := range turns {
+			res.addMove(Turn{Kind: g.Kind, Face: g.Face, Turns: t})
+		}
+	}
+	if len(res.moves) == 0 {
+		return nil, errors.New("no generators")
+	}
+	res.group = newPermGroup(genPerms)
+	res.computeFollows()
+
+	for _, slots := range stickerPieces {
+		var moved [][]int
+		for _, stickers := range slots {
+			if res.movesSticker(stickers[0]) {
+				moved = append(moved, stickers)
+			}
+		}
+		if len(moved) == 0 {
+			continue
+		}
+		pieces := make([]int, len(moved))
+		for i := range moved {
+			pieces[i] = len(res.pieces) + i
+		}
+		for _, stickers := range moved {
+			res.pieces = append(res.pieces, stickers[0])
+		}
+		for len(pieces) > 0 {
+			count := len(pieces)
+			for subgroupDatabaseEntries(len(moved), len(moved[0]), count) >
+				subgroupDatabaseSize {
+				count--
+			}
+			res.databases = append(res.databases,
+				newSubgroupDatabase(res, moved, pieces[:count]))
+			pieces = pieces[count:]
+		}
+	}
+	return res, nil
+}
+
+// Contains checks if a cube can be solved using the generators. An invalid
+// cube is never contained.
+func (s *SubgroupTables) Contains(o *OrientedCube) bool {
+	if o.Validate() != nil {
+		return false
+	}
+	return s.group.contains(stickerPermutation(o))
+}
+
+// Order returns the number of states in the subgroup.
+func (s *SubgroupTables) Order() *big.Int {
+	return s.group.order()
+}
+
+// LowerBound returns the minimum number of moves needed to solve a cube in the
+// subgroup. The cube must be valid.
+func (s *SubgroupTables) LowerBound(o *OrientedCube) int {
+	node := s.newNode(stickerPermutation(o))
+	return s.nodeLowerBound(&node)
+}
+
+func (s *SubgroupTables) addMove(t Turn) {
+	for _, m := range s.moves {
+		if m == t {
+			return
+		}
+	}
+	s.moves = append(s.moves, t)
+	s.movePerms = append(s.movePerms, turnStickerPerm(t))
+}
+
+// computeFollows prevents consecutive moves of the same layer. Turns around
+// the same axis commute, so they are only searched in one order.
+func (s *SubgroupTables) computeFollows() {
+	layer := func(t Turn) int {
+		return int(t.Kind)*6 + t.Face
+	}
+	s.follows = make([][]bool, len(s.moves)+1)
+	for i := range s.follows {
+		s.follows[i] = make([]bool, len(s.moves))
+		for j, next := range s.moves {
+			if i == 0 {
+				s.follows[i][j] = true
+				continue
+			}
+			last := s.moves[i-1]
+			if layer(last) == layer(next) {
+				continue
+			}
+			if last.Axis() == next.Axis() && layer(next) < layer(last) {
+				continue
+			}
+			s.follows[i][j] = true
+		}
+	}
+}
+
+func (s *SubgroupTables) movesSticker(sticker int) bool {
+	for _, p := range s.movePerms {
+		if int(p[sticker]) != sticker {
+			return true
+		}
+	}
+	return false
+}
+
+// A subgroupNode stores the position of the reference sticker of every piece
+// which the generators can move.
+type subgroupNode [26]uint8
+
+func (s *SubgroupTables) newNode(p stickerPerm) subgroupNode {
+	var res subgroupNode
+	for i, sticker := range s.pieces {
+		res[i] = p[sticker]
+	}
+	return res
+}
+
+func (s *SubgroupTables) moveNode(n *subgroupNode, move int) {
+	perm := &s.movePerms[move]
+	for i := range s.pieces {
+		n[i] = perm[n[i]]
+	}
+}
+
+func (s *SubgroupTables) nodeSolved(n *subgroupNode) bool {
+	for i, sticker := range s.pieces {
+		if int(n[i]) != sticker {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *SubgroupTables) nodeLowerBound(n *subgroupNode) int {
+	var res int
+	for _, d := range s.databases {
+		if bound := int(d.data[d.index(n)]); bound > res {
+			res = bound
+		}
+	}
+	return res
+}
+
+// A subgroupDatabase stores the number of moves needed to solve some of the
+// pieces of one kind.
+//
+// An entry is indexed by the slot of each tracked piece, out of the slots which
+// the generators move, and by which sticker of the slot holds the piece's
+// reference sticker.
+type subgroupDatabase struct {
+	// pieces are indices into a subgroupNode.
+	pieces []int
+
+	slotCount   int
+	orientCount int
+
+	// stickerSlot and stickerOrient map a sticker position to a slot and to
+	// the index of the sticker in that slot.
+	stickerSlot   [54]int8
+	stickerOrient [54]int8
+
+	// slotStickers maps a slot and orientation back to a sticker position.
+	slotStickers [][]int
+
+	data byteTable
+}
+
+func subgroupDatabaseEntries(slotCount, orientCount, pieceCount int) int {
+	res := partialPermutationCount(slotCount, pieceCount)
+	for i := 0; i < pieceCount; i++ {
+		res *= orientCount
+	}
+	return res
+}
+
+func newSubgroupDatabase(tables *SubgroupTables, slots [][]int,
+	pieces []int) *subgroupDatabase {
+	res := &subgroupDatabase{
+		pieces:       pieces,
+		slotCount:    len(slots),
+		orientCount:  len(slots[0]),
+		slotStickers: slots,
+	}
+	for i := range res.stickerSlot {
+		res.stickerSlot[i] = -1
+	}
+	for slot, stickers := range slots {
+		for orient, sticker := range stickers {
+			res.stickerSlot[sticker] = int8(slot)
+			res.stickerOrient[sticker] = int8(orient)
+		}
+	}
+
+	var solved subgroupNode
+	for i, sticker := range tables.pieces {
+		solved[i] = uint8(sticker)
+	}
+
+	size := subgroupDatabaseEntries(res.slotCount, res.orientCount, len(pieces))
+	res.data = newByteTable(size, 0xff)
+	var node subgroupNode
+	fillPatternDatabase(res.data, size, res.index(&solved), len(tables.moves),
+		func(idx int, neighbors []int) {
+			res.decode(idx, &node)
+			for m := range neighbors {
+				next := node
+				tables.moveNode(&next, m)
+				neighbors[m] = res.index(&next)
+			}
+		})
+	return res
+}
+
+func (s *subgroupDatabase) index(n *subgroupNode) int {
+	var slots [26]int
+	var orients int
+	for i, piece := range s.pieces {
+		sticker := n[piece]
+		slots[i] = int(s.stickerSlot[sticker])
+		orients = orients*s.orientCount + int(s.stickerOrient[sticker])
+	}
+	perm := encodePartialPermutation(slots[:len(s.pieces)], s.slotCount)
+	for range s.pieces {
+		perm *= s.orientCount
+	}
+	return perm + orients
+}
+
+// decode sets the tracked pieces of a node from an index.
+func (s *subgroupDatabase) decode(idx int, n *subgroupNode) {
+	var orients [26]int
+	for i := len(s.pieces) - 1; i >= 0; i-- {
+		orients[i] = idx % s.orientCount
+		idx /= s.orientCount
+	}
+	var slots [26]int
+	decodePartialPermutation(idx, s.slotCount, slots[:len(s.pieces)])
+	for i, piece := range s.pieces {
+		n[piece] = uint8(s.slotStickers[slots[i]][orients[i]])
+	}
+}
+
+// A SubgroupSolver finds the shortest solutions to a cube which only use the
+// turns of a SubgroupTables, using iterative deepening A* search.
+type SubgroupSolver struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	solutions  chan Algorithm
+	lowerBound int32
+	reason     int32
+
+	start    subgroupNode
+	tables   *SubgroupTables
+	observer *PhaseObserver
+}
+
+// NewSubgroupSolver creates and starts a SubgroupSolver.
+//
+// If the cube is invalid, this returns the error from OrientedCube.Validate.
+// If the cube cannot be solved with the generators, ErrNotInSubgroup is
+// returned.
+func NewSubgroupSolver(o OrientedCube,
+	tables *SubgroupTables) (*SubgroupSolver, error) {
+	return NewSubgroupSolverContext(context.Background(), o, tables, nil)
+}
+
+// NewSubgroupSolverContext is like NewSubgroupSolver, but the solver stops
+// when ctx is done.
+//
+// If observer is non-nil, the search reports to its phase 0. A new depth is
+// started once every shorter solution has been ruled out, so OnDepth reports
+// each completed depth as it happens.
+func NewSubgroupSolverContext(ctx context.Context, o OrientedCube,
+	tables *SubgroupTables, observer *SearchObserver) (*SubgroupSolver, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	perm := stickerPermutation(&o)
+	if !tables.group.contains(perm) {
+		return nil, ErrNotInSubgroup
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	res := &SubgroupSolver{
+		ctx:       ctx,
+		cancel:    cancel,
+		solutions: make(chan Algorithm),
+		start:     tables.newNode(perm),
+		tables:    tables,
+		observer:  observer.Phase(0),
+	}
+	go res.search()
+	return res, nil
+}
+
+// SolveSubgroup finds one of the shortest solutions to a cube which only uses
+// the turns of a SubgroupTables, blocking until it is found.
+func SolveSubgroup(o OrientedCube, tables *SubgroupTables) (Algorithm, error) {
+	solver, err := NewSubgroupSolver(o, tables)
+	if err != nil {
+		return nil, err
+	}
+	solution := <-solver.Solutions()
+	solver.Stop()
+	return solution, nil
+}
+
+// Solutions is a channel over which every optimal solution is delivered. The
+// channel is closed once every solution of the optimal length has been found,
+// or when the solver is stopped.
+func (s *SubgroupSolver) Solutions() <-chan Algorithm {
+	return s.solutions
+}
+
+// LowerBound returns the minimum length of a solution, based on the search
+// depths which have been exhausted so far.
+func (s *SubgroupSolver) LowerBound() int {
+	return int(atomic.LoadInt32(&s.lowerBound))
+}
+
+// Stop stops the solver. It may be called more than once.
+func (s *SubgroupSolver) Stop() {
+	s.cancel()
+}
+
+// Reason returns the reason that the solver stopped. It is StopExhausted if
+// every optimal solution was found, and StopCanceled if the solver was stopped
+// first. It returns StopNone until the Solutions channel has been closed.
+func (s *SubgroupSolver) Reason() StopReason {
+	return StopReason(atomic.LoadInt32(&s.reason))
+}
+
+func (s *SubgroupSolver) search() {
+	reason := StopCanceled
+	defer func() {
+		atomic.StoreInt32(&s.reason, int32(reason))
+		s.cancel()
+		close(s.solutions)
+	}()
+	defer s.observer.EndSearch(s.observer.StartSearch())
+
+	depth := s.tables.nodeLowerBound(&s.start)
+	for {
+		atomic.StoreInt32(&s.lowerBound, int32(depth))
+		s.observer.StartDepth(depth)
+		moves := make([]int, 0, depth)
+		found, ok := s.depthFirst(s.start, moves, depth)
+		if !ok {
+			return
+		} else if found {
+			reason = StopExhausted
+			return
+		}
+		depth++
+	}
+}
+
+// depthFirst searches for solutions of a given length. It returns whether any
+// solutions were found, and false for ok if the search was stopped.
+func (s *SubgroupSolver) depthFirst(n subgroupNode, moves []int,
+	depth int) (found, ok bool) {
+	s.observer.Node()
+	if depth == 0 {
+		if !s.tables.nodeSolved(&n) {
+			return false, true
+		}
+		res := make(Algorithm, len(moves))
+		for i, m := range moves {
+			res[i] = s.tables.moves[m]
+		}
+		select {
+		case <-s.ctx.Done():
+			return true, false
+		case s.solutions <- res:
+		}
+		return true, true
+	}
+
+	if s.tables.nodeLowerBound(&n) > depth {
+		s.observer.Prune()
+		return false, true
+	}
+
+	last := 0
+	if len(moves) > 0 {
+		last = moves[len(moves)-1] + 1
+	}
+	for m, allowed := range s.tables.follows[last] {
+		if !allowed {
+			continue
+		}
+		next := n
+		s.tables.moveNode(&next, m)
+		subFound, subOK := s.depthFirst(next, append(moves, m), depth-1)
+		found = found || subFound
+		if !subOK {
+			return found, false
+		}
+		if depth >= 6 && s.ctx.Err() != nil {
+			return found, false
+		}
+	}
+	return found, true
+}
+
+// stickerPermutation finds the position of every sticker of an OrientedCube,
+// relative to a solved cube in the standard orientation. The cube must be
+// valid.
+func stickerPermutation(o *OrientedCube) stickerPerm {
+	stickers := o.StickerCube()
+	solved := SolvedStickerCube()
+	var res stickerPerm
+	for _, slots := range stickerPieces {
+		for _, slot := range slots {
+			piece := findStickerPiece(slots, &stickers, slot)
+			for _, position := range slot {
+				for _, home := range piece {
+					if solved[home] == stickers[position] {
+						res[home] = uint8(position)
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// findStickerPiece finds the slot whose solved colors match the colors in
+// another slot.
+func findStickerPiece(slots [][]int, stickers *StickerCube, slot []int) []int {
+	solved := SolvedStickerCube()
+	colors := make([]int, len(slot))
+	for i, position := range slot {
+		colors[i] = stickers[position]
+	}
+	for _, home := range slots {
+		homeColors := make([]int, len(home))
+		for i, position := range home {
+			homeColors[i] = solved[position]
+		}
+		if setsEqual(colors, homeColors) {
+			return home
+		}
+	}
+	panic("oriented cube has an impossible piece")
+}
+
+// turnStickerPerm finds the sticker permutation caused by a turn.
+func turnStickerPerm(t Turn) stickerPerm {
+	cube := SolvedOrientedCube()
+	cube.Turn(t)
+	return stickerPermutation(&cube)
+}
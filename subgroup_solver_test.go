@@ -0,0 +1,174 @@
+package gocube
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestSubgroupSolver(t *testing.T) {
+	scrambles := map[string]string{
+		"R U":  "R U2 R' U' R U' R' U2 R2 U R U",
+		"M U":  "M' U M2 U2 M' U' M U2 M2",
+		"R2 U": "R2 U R2 U' R2 U2 R2",
+		"Rw U": "Rw U Rw' U' Rw2 U",
+	}
+	for gens, scramble := range scrambles {
+		generators, err := ParseAlgorithm(gens)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables, err := NewSubgroupTables(generators)
+		if err != nil {
+			t.Fatal(err)
+		}
+		alg, err := ParseAlgorithm(scramble)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cube := SolvedOrientedCube()
+		cube.Apply(alg)
+		if !tables.Contains(&cube) {
+			t.Errorf("<%s>: scramble should be in the subgroup", gens)
+			continue
+		}
+		bound := tables.LowerBound(&cube)
+		solution, err := SolveSubgroup(cube, tables)
+		if err != nil {
+			t.Errorf("<%s>: %v", gens, err)
+			continue
+		}
+		if len(solution) > len(alg) || len(solution) < bound {
+			t.Errorf("<%s>: unexpected solution length: %s", gens, solution)
+		}
+		for _, turn := range solution {
+			var generated bool
+			for _, g := range generators {
+				if turn.Kind == g.Kind && turn.Face == g.Face &&
+					(g.Turns != 2 || turn.Turns == 2) {
+					generated = true
+				}
+			}
+			if !generated {
+				t.Errorf("<%s>: solution uses other turns: %s", gens, solution)
+				break
+			}
+		}
+		cube.Apply(solution)
+		if !cube.Solved() || cube.Orientation != StandardOrientation() {
+			t.Errorf("<%s>: solution %s does not work", gens, solution)
+		}
+	}
+}
+
+func TestSubgroupSolverNotInSubgroup(t *testing.T) {
+	generators, _ := ParseAlgorithm("R U")
+	tables, err := NewSubgroupTables(generators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scramble := range []string{"F", "R U F'", "R U R' U' M"} {
+		alg, _ := ParseAlgorithm(scramble)
+		cube := SolvedOrientedCube()
+		cube.Apply(alg)
+		if _, err := NewSubgroupSolver(cube, tables); err != ErrNotInSubgroup {
+			t.Errorf("%s: unexpected error: %v", scramble, err)
+		}
+	}
+
+	// The T permutation only moves pieces which <R,U> can move, but it swaps
+	// two corners, which <R,U> cannot do.
+	alg, _ := ParseAlgorithm("R U R' U' R' F R2 U' R' U' R U R' F'")
+	cube := SolvedOrientedCube()
+	cube.Apply(alg)
+	if tables.Contains(&cube) {
+		t.Error("T permutation should not be in <R,U>")
+	}
+}
+
+func TestSubgroupSolverInvalid(t *testing.T) {
+	generators, _ := ParseAlgorithm("R U")
+	tables, err := NewSubgroupTables(generators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube := SolvedOrientedCube()
+	cube.Cube.Corners[0].Piece = 1
+	if tables.Contains(&cube) {
+		t.Error("invalid cube should not be in the subgroup")
+	}
+	if _, err := NewSubgroupSolver(cube, tables); err == nil {
+		t.Error("expected an error for an invalid cube")
+	}
+	cube = SolvedOrientedCube()
+	cube.Orientation[0] = cube.Orientation[1]
+	if _, err := NewSubgroupSolver(cube, tables); err == nil {
+		t.Error("expected an error for an invalid orientation")
+	}
+}
+
+func TestSubgroupSolverContext(t *testing.T) {
+	generators, _ := ParseAlgorithm("R U")
+	tables, err := NewSubgroupTables(generators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alg, _ := ParseAlgorithm("R U2 R' U' R U' R' U2 R2 U R U")
+	cube := SolvedOrientedCube()
+	cube.Apply(alg)
+
+	t.Run("Depths", func(t *testing.T) {
+		var lock sync.Mutex
+		var depths []int
+		observer := &SearchObserver{}
+		observer.OnDepth = func(phase, depth int) {
+			lock.Lock()
+			depths = append(depths, depth)
+			lock.Unlock()
+		}
+		solver, err := NewSubgroupSolverContext(context.Background(), cube,
+			tables, observer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var length int
+		for solution := range solver.Solutions() {
+			length = len(solution)
+		}
+		if solver.Reason() != StopExhausted {
+			t.Errorf("unexpected reason: %s", solver.Reason())
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if len(depths) == 0 || depths[len(depths)-1] != length {
+			t.Fatalf("unexpected depths %v for length %d", depths, length)
+		}
+		for i := 1; i < len(depths); i++ {
+			if depths[i] != depths[i-1]+1 {
+				t.Errorf("unexpected depths %v", depths)
+			}
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		solver, err := NewSubgroupSolverContext(ctx, cube, tables, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for solution := range solver.Solutions() {
+			t.Errorf("unexpected solution %v", solution)
+		}
+		if solver.Reason() != StopCanceled {
+			t.Errorf("unexpected reason: %s", solver.Reason())
+		}
+	})
+}
+
+func TestSubgroupTablesRotation(t *testing.T) {
+	generators, _ := ParseAlgorithm("R x")
+	if _, err := NewSubgroupTables(generators); err == nil {
+		t.Error("expected an error for a rotation")
+	}
+}
@@ -0,0 +1,207 @@
+package gocube
+
+import (
+	"errors"
+	"strings"
+)
+
+// centerMarks are the characters used to show the orientation of a center in
+// a sticker string. Each mark shows which way the top of the center points.
+const centerMarks = "^>v<"
+
+// A SuperCube is a cube on which the orientation of each center matters, such
+// as a picture cube or a supercube.
+type SuperCube struct {
+	Cube CubieCube
+
+	// Centers stores the number of clockwise quarter turns (0 through 3) of
+	// each center, indexed by face-1. A clockwise turn is as seen when
+	// looking directly at the face.
+	Centers [6]int
+}
+
+// SolvedSuperCube returns a solved SuperCube.
+func SolvedSuperCube() SuperCube {
+	return SuperCube{Cube: SolvedCubieCube()}
+}
+
+// ParseSuperCube parses a space-delimited list of faces, like
+// ParseStickerCube. The center of each face may be replaced by one of the
+// characters ^, >, v, or < to show which way the top of the center points.
+// A center with a color instead of a mark is not twisted.
+//
+// For the U and D faces, the top of a center points towards B and F
+// respectively in the solved state. For the other faces, it points towards U.
+func ParseSuperCube(str string) (*SuperCube, error) {
+	faceStrs := strings.Split(str, " ")
+	if len(faceStrs) != 6 {
+		return nil, errors.New("input must have six faces.")
+	}
+
+	var res SuperCube
+	var stickers StickerCube
+	for i, faceStr := range faceStrs {
+		runes := []rune(faceStr)
+		if len(runes) > 4 {
+			if twist := strings.IndexRune(centerMarks, runes[4]); twist >= 0 {
+				res.Centers[i] = twist
+				runes[4] = rune('1' + i)
+			}
+		}
+		list, err := parseFace(string(runes), i+1)
+		if err != nil {
+			return nil, err
+		}
+		copy(stickers[i*9:i*9+9], list)
+	}
+	if err := stickers.Validate(); err != nil {
+		return nil, err
+	}
+	cube, _ := stickers.CubieCube()
+	res.Cube = *cube
+	return &res, nil
+}
+
+// String generates a space-delimited list of faces, marking the orientation of
+// each center as described in ParseSuperCube.
+func (s *SuperCube) String() string {
+	stickers := s.Cube.StickerCube()
+	res := []rune(stickers.String())
+	for i, twist := range s.Centers {
+		res[i*10+4] = rune(centerMarks[twist])
+	}
+	return string(res)
+}
+
+// Move applies a move to the cube, twisting the center of the turned face.
+func (s *SuperCube) Move(m Move) {
+	s.Cube.Move(m)
+	face := m.Face() - 1
+	s.Centers[face] = (s.Centers[face] + m.Turns() + 4) % 4
+}
+
+// Solved returns true if the pieces and the centers are solved.
+func (s *SuperCube) Solved() bool {
+	return s.Cube.Solved() && s.Centers == [6]int{}
+}
+
+// Validate checks that the cube could be reached from the solved state by
+// turning faces.
+//
+// In addition to the errors returned by CubieCube.Validate, this may return a
+// *CenterTwistError.
+func (s *SuperCube) Validate() error {
+	if err := s.Cube.Validate(); err != nil {
+		return err
+	}
+	var twist int
+	for _, t := range s.Centers {
+		if t < 0 || t > 3 {
+			return &CenterTwistError{s.Centers}
+		}
+		twist += t
+	}
+	if (twist%2 == 0) != s.Cube.Corners.Parity() {
+		return &CenterTwistError{s.Centers}
+	}
+	return nil
+}
+
+// CenterSolution returns moves which solve the centers of a SuperCube whose
+// pieces are already solved, without disturbing the pieces.
+//
+// The centers must satisfy Validate(), so the twists must add up to an even
+// number.
+func CenterSolution(centers [6]int) []Move {
+	var res []Move
+	apply := func(moves []Move) {
+		res = append(res, moves...)
+		for _, m := range moves {
+			face := m.Face() - 1
+			centers[face] = (centers[face] + m.Turns() + 4) % 4
+		}
+	}
+
+	// Pair up centers which are off by a quarter turn.
+	for f := 0; f < 6; f++ {
+		if centers[f]%2 == 0 {
+			continue
+		}
+		for g := f + 1; g < 6; g++ {
+			if centers[g]%2 == 1 {
+				apply(centerMacro(centerQuarterMacros, f+1, g+1, 4-centers[f]))
+				break
+			}
+		}
+	}
+
+	// Fix pairs of half-turned centers, and then any single ones.
+	for f := 0; f < 6; f++ {
+		if centers[f] != 2 {
+			continue
+		}
+		for g := f + 1; g < 6; g++ {
+			if centers[g] == 2 {
+				apply(centerMacro(centerHalfPairMacros, f+1, g+1, 2))
+				break
+			}
+		}
+		if centers[f] == 2 {
+			apply(centerMacro(centerHalfMacros, f+1, 0, 2))
+		}
+	}
+	return res
+}
+
+// A centerMacroMoves is a sequence of moves which twists one or two centers
+// without affecting anything else.
+type centerMacroMoves struct {
+	moves string
+
+	// faces lists the twisted faces. The first face is always U.
+	faces []int
+}
+
+// centerHalfMacros twist a single center by a half turn.
+var centerHalfMacros = []centerMacroMoves{
+	{"U F B U2 F' B' U B F U2 B' F'", []int{1}},
+}
+
+// centerHalfPairMacros twist two centers by a half turn each.
+var centerHalfPairMacros = []centerMacroMoves{
+	{"U2 F2 R2 L2 B2 D2 B2 L2 R2 F2", []int{1, 2}},
+	{"R L' F2 D2 B2 L R' U2 B2 D2", []int{1, 3}},
+}
+
+// centerQuarterMacros twist the U center clockwise and another center
+// counter-clockwise.
+var centerQuarterMacros = []centerMacroMoves{
+	{"U F B R2 L2 F' B' D' B F L2 R2 B' F'", []int{1, 2}},
+	{"U D R L' F' B' U F B L R' D' U' F'", []int{1, 3}},
+}
+
+// centerMacro translates one of the macros through a symmetry so that it
+// twists face1 (and face2, if it is non-zero), where face1 is twisted by the
+// given number of clockwise quarter turns.
+func centerMacro(macros []centerMacroMoves, face1, face2, turns int) []Move {
+	for _, macro := range macros {
+		moves, _ := ParseMoves(macro.moves)
+		inverse, _ := NewAlgorithm(moves).Inverse().Moves()
+		for s := Symmetry(0); s < SymmetryCount; s++ {
+			if s.Face(1) != face1 ||
+				(face2 != 0 && s.Face(macro.faces[len(macro.faces)-1]) != face2) {
+				continue
+			}
+			for _, candidate := range [][]Move{s.Moves(moves), s.Moves(inverse)} {
+				cube := SolvedSuperCube()
+				for _, m := range candidate {
+					cube.Move(m)
+				}
+				if cube.Centers[face1-1] == turns%4 {
+					return candidate
+				}
+			}
+		}
+	}
+	panic("no center macro for faces")
+}
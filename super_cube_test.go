@@ -0,0 +1,153 @@
+package gocube
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSuperCubeStrings(t *testing.T) {
+	cube := SolvedSuperCube()
+	cube.Move(NewMove(1, 1))
+	cube.Move(NewMove(5, 2))
+	cube.Move(NewMove(3, -1))
+	stickers := cube.Cube.StickerCube()
+	plain := stickers.String()
+	for i, mark := range ">^<^v^" {
+		plain = plain[:i*10+4] + string(mark) + plain[i*10+5:]
+	}
+	if s := cube.String(); s != plain {
+		t.Errorf("unexpected string %q (expected %q)", s, plain)
+	}
+
+	for i := 0; i < 20; i++ {
+		cube := SolvedSuperCube()
+		for j := 0; j < 30; j++ {
+			cube.Move(Move(rand.Intn(18)))
+		}
+		parsed, err := ParseSuperCube(cube.String())
+		if err != nil {
+			t.Error(err)
+		} else if *parsed != cube {
+			t.Errorf("%s did not round trip", cube.String())
+		}
+	}
+
+	parsed, err := ParseSuperCube("1 2222v 3 4 5555< 6")
+	if err != nil {
+		t.Fatal(err)
+	} else if parsed.Centers != [6]int{0, 2, 0, 0, 3, 0} {
+		t.Errorf("unexpected centers %v", parsed.Centers)
+	}
+}
+
+func TestSuperCubeValidate(t *testing.T) {
+	cube := SolvedSuperCube()
+	if err := cube.Validate(); err != nil {
+		t.Error(err)
+	}
+	cube.Centers[0] = 1
+	if _, ok := cube.Validate().(*CenterTwistError); !ok {
+		t.Error("expected center twist error")
+	}
+	cube.Centers[0] = 0
+	cube.Move(NewMove(1, 1))
+	if err := cube.Validate(); err != nil {
+		t.Error(err)
+	}
+	cube.Centers[1] = 2
+	if err := cube.Validate(); err != nil {
+		t.Error(err)
+	}
+	cube.Centers[1] = 4
+	if _, ok := cube.Validate().(*CenterTwistError); !ok {
+		t.Error("expected center twist error")
+	}
+}
+
+func TestCenterSolution(t *testing.T) {
+	for i := 0; i < 1<<12; i++ {
+		var centers [6]int
+		var sum int
+		for j := range centers {
+			centers[j] = (i >> uint(j*2)) & 3
+			sum += centers[j]
+		}
+		if sum%2 != 0 {
+			continue
+		}
+		cube := SuperCube{Cube: SolvedCubieCube(), Centers: centers}
+		for _, m := range CenterSolution(centers) {
+			cube.Move(m)
+		}
+		if !cube.Solved() {
+			t.Errorf("failed to solve centers %v", centers)
+		}
+	}
+}
+
+func TestSuperSolver(t *testing.T) {
+	tables := *sharedSolverTables(HTM)
+	for i := 0; i < 5; i++ {
+		cube := SolvedSuperCube()
+		for j := 0; j < 30; j++ {
+			cube.Move(Move(rand.Intn(18)))
+		}
+		solver := NewSuperSolverTables(cube, 24, tables)
+		solution := <-solver.Solutions()
+		solver.Stop()
+		for _, m := range solution {
+			cube.Move(m)
+		}
+		if !cube.Solved() {
+			t.Errorf("%d: solution %v resulted in %s", i, solution, cube.String())
+		}
+	}
+}
+
+func TestSuperSolverContext(t *testing.T) {
+	cube := SolvedSuperCube()
+	for j := 0; j < 30; j++ {
+		cube.Move(Move(rand.Intn(18)))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	solver := NewSuperSolverContext(ctx, cube, SolveOptions{})
+	solution, ok := <-solver.Solutions()
+	if !ok {
+		t.Fatal("no solution was found")
+	}
+	cancel()
+	for range solver.Solutions() {
+	}
+	for _, m := range solution {
+		cube.Move(m)
+	}
+	if !cube.Solved() {
+		t.Errorf("solution %v resulted in %s", solution, cube.String())
+	}
+	solver.Stop()
+}
+
+func TestSuperSolverMetric(t *testing.T) {
+	cube := SolvedSuperCube()
+	for j := 0; j < 30; j++ {
+		cube.Move(Move(rand.Intn(18)))
+	}
+	solver := NewSuperSolverContext(context.Background(), cube, SolveOptions{
+		Metric:    QTM,
+		TimeLimit: time.Second,
+		Tables:    sharedSolverTables(QTM),
+	})
+	last := -1
+	for solution := range solver.Solutions() {
+		length := QTM.Length(solution)
+		if last >= 0 && length >= last {
+			t.Errorf("solution of length %d came after %d", length, last)
+		}
+		last = length
+	}
+	if last < 0 {
+		t.Error("no solution was found")
+	}
+}
@@ -0,0 +1,100 @@
+package gocube
+
+import "context"
+
+// A SuperSolver finds shorter and shorter solutions to a SuperCube in the
+// background.
+//
+// It uses a Solver to solve the pieces, and then appends moves which fix the
+// centers.
+type SuperSolver struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	solutions chan []Move
+	solver    *Solver
+}
+
+// NewSuperSolver creates a new solver for a SuperCube. The max argument limits
+// the length of the solutions for the pieces, not including the moves which
+// fix the centers.
+func NewSuperSolver(c SuperCube, max int) *SuperSolver {
+	p1Moves := NewPhase1Moves()
+	p2Moves := NewPhase2Moves()
+	return NewSuperSolverTables(c, max, SolverTables{
+		P1Heuristic: NewPhase1Heuristic(p1Moves),
+		P1Moves:     p1Moves,
+		P2Heuristic: NewPhase2Heuristic(p2Moves),
+		P2Moves:     p2Moves,
+	})
+}
+
+// NewSuperSolverTables is like NewSuperSolver, but it uses a set of
+// pre-generated tables.
+func NewSuperSolverTables(c SuperCube, max int, tables SolverTables) *SuperSolver {
+	return NewSuperSolverContext(context.Background(), c, SolveOptions{
+		MaxLength: max,
+		Metric:    tables.Metric,
+		Tables:    &tables,
+	})
+}
+
+// NewSuperSolverContext creates and starts a solver for a SuperCube which is
+// configured by opts, like NewSolverContext. The options apply to the solution
+// for the pieces, not including the moves which fix the centers.
+//
+// The solver stops when ctx is done, and the Solutions channel is closed.
+func NewSuperSolverContext(ctx context.Context, c SuperCube,
+	opts SolveOptions) *SuperSolver {
+	ctx, cancel := context.WithCancel(ctx)
+	res := &SuperSolver{
+		ctx:       ctx,
+		cancel:    cancel,
+		solutions: make(chan []Move),
+		solver:    NewSolverContext(ctx, c.Cube, opts),
+	}
+	go res.backgroundLoop(c)
+	return res
+}
+
+// Solutions is a channel over which shorter and shorter solutions are
+// delivered. It is closed when the solver stops.
+func (s *SuperSolver) Solutions() <-chan []Move {
+	return s.solutions
+}
+
+// Stop stops the solver. It may be called more than once.
+func (s *SuperSolver) Stop() {
+	s.cancel()
+}
+
+func (s *SuperSolver) backgroundLoop(c SuperCube) {
+	defer func() {
+		s.cancel()
+		close(s.solutions)
+	}()
+	best := -1
+	for pieceSolution := range s.solver.Solutions() {
+		cube := c
+		for _, m := range pieceSolution {
+			cube.Move(m)
+		}
+		joined := append(append([]Move{}, pieceSolution...),
+			CenterSolution(cube.Centers)...)
+		// Solutions are compared in the solver's metric or move costs, in
+		// which merging moves may make a solution longer.
+		solution, _ := NewAlgorithm(joined).Simplify().Moves()
+		length := s.solver.length(0, solution)
+		if joinedLength := s.solver.length(0, joined); joinedLength < length {
+			solution, length = joined, joinedLength
+		}
+		if best >= 0 && length >= best {
+			continue
+		}
+		best = length
+		select {
+		case <-s.ctx.Done():
+			return
+		case s.solutions <- solution:
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package gocube
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SVGOptions controls how a cube is rendered as an SVG image.
+type SVGOptions struct {
+	// Colors contains the fill color of each sticker color, indexed by sticker
+	// value (so the first entry is unused). Any SVG color may be used.
+	Colors [7]string
+
+	// StickerSize is the width of each sticker.
+	StickerSize float64
+
+	// Gap is the width of the border around each sticker.
+	Gap float64
+
+	// FaceGap is the space between faces, and around the edge of the image.
+	FaceGap float64
+
+	// BorderColor is drawn behind the stickers of each face, so it shows
+	// through the gaps between them.
+	BorderColor string
+
+	// Background is the color of the whole image. If it is empty, the image
+	// is transparent.
+	Background string
+
+	// Mask indicates which stickers (indexed like a StickerCube) should be
+	// drawn in MaskColor instead of their own colors.
+	Mask [54]bool
+
+	// MaskColor is used for masked stickers and for stickers with invalid
+	// values.
+	MaskColor string
+
+	// ArrowColor is used for arrows in diagrams which show the movement of
+	// pieces.
+	ArrowColor string
+}
+
+// DefaultSVGOptions returns the options used when no options are given. The
+// colors are the standard color scheme used on WCA scramble sheets.
+func DefaultSVGOptions() *SVGOptions {
+	return &SVGOptions{
+		Colors: [7]string{"", "#ffffff", "#ffff00", "#00ff00", "#0000ff",
+			"#ff0000", "#ff8000"},
+		StickerSize: 20,
+		Gap:         2,
+		FaceGap:     6,
+		BorderColor: "#000000",
+		MaskColor:   "#808080",
+		ArrowColor:  "#000000",
+	}
+}
+
+// SVG renders the stickers as an unfolded net, laid out like Net().
+//
+// If opts is nil, DefaultSVGOptions() is used.
+func (s *StickerCube) SVG(opts *SVGOptions) string {
+	if opts == nil {
+		opts = DefaultSVGOptions()
+	}
+	faceSize := 3*opts.StickerSize + 4*opts.Gap
+	width := 4*faceSize + 5*opts.FaceGap
+	height := 3*faceSize + 4*opts.FaceGap
+
+	var res strings.Builder
+	fmt.Fprintf(&res, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" `+
+		`height="%s" viewBox="0 0 %s %s">`+"\n", svgNumber(width),
+		svgNumber(height), svgNumber(width), svgNumber(height))
+	if opts.Background != "" {
+		writeSVGRect(&res, 0, 0, width, height, opts.Background)
+	}
+
+	for row, faces := range netRows {
+		for col, face := range faces {
+			if len(faces) == 1 {
+				col = 1
+			}
+			faceX := opts.FaceGap + float64(col)*(faceSize+opts.FaceGap)
+			faceY := opts.FaceGap + float64(row)*(faceSize+opts.FaceGap)
+			writeSVGRect(&res, faceX, faceY, faceSize, faceSize,
+				opts.BorderColor)
+			for i := 0; i < 9; i++ {
+				idx := (face-1)*9 + i
+				x := faceX + opts.Gap + float64(i%3)*(opts.StickerSize+opts.Gap)
+				y := faceY + opts.Gap + float64(i/3)*(opts.StickerSize+opts.Gap)
+				writeSVGRect(&res, x, y, opts.StickerSize, opts.StickerSize,
+					opts.stickerColor(s[idx], opts.Mask[idx]))
+			}
+		}
+	}
+
+	res.WriteString("</svg>\n")
+	return res.String()
+}
+
+// SVG renders the cube as an unfolded net. See StickerCube.SVG for details.
+func (c *CubieCube) SVG(opts *SVGOptions) string {
+	stickers := c.StickerCube()
+	return stickers.SVG(opts)
+}
+
+// ScrambleSVG renders the state which results from applying a scramble to a
+// solved cube, like the images on a WCA scramble sheet.
+func ScrambleSVG(scramble []Move, opts *SVGOptions) string {
+	cube := SolvedCubieCube()
+	for _, m := range scramble {
+		cube.Move(m)
+	}
+	return cube.SVG(opts)
+}
+
+func (s *SVGOptions) stickerColor(sticker int, masked bool) string {
+	if masked || sticker < 1 || sticker > 6 {
+		return s.MaskColor
+	}
+	return s.Colors[sticker]
+}
+
+func writeSVGRect(w *strings.Builder, x, y, width, height float64,
+	fill string) {
+	fmt.Fprintf(w, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+
+		"\n", svgNumber(x), svgNumber(y), svgNumber(width), svgNumber(height),
+		svgEscape(fill))
+}
+
+func svgNumber(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;",
+		`"`, "&quot;").Replace(s)
+}
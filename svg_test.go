@@ -0,0 +1,66 @@
+package gocube
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+type svgImage struct {
+	Width  string    `xml:"width,attr"`
+	Height string    `xml:"height,attr"`
+	Rects  []svgRect `xml:"rect"`
+}
+
+type svgRect struct {
+	X    string `xml:"x,attr"`
+	Y    string `xml:"y,attr"`
+	Fill string `xml:"fill,attr"`
+}
+
+func TestStickerCubeSVG(t *testing.T) {
+	cube := SolvedCubieCube()
+	cube.Move(NewMove(1, 1))
+	stickers := cube.StickerCube()
+
+	opts := DefaultSVGOptions()
+	opts.Background = "white"
+	opts.Mask[9*2+4] = true
+	var image svgImage
+	if err := xml.Unmarshal([]byte(stickers.SVG(opts)), &image); err != nil {
+		t.Fatal(err)
+	}
+	if image.Width != "302" || image.Height != "228" {
+		t.Errorf("unexpected size %sx%s", image.Width, image.Height)
+	}
+	if len(image.Rects) != 1+6*10 {
+		t.Fatalf("unexpected number of rectangles: %d", len(image.Rects))
+	}
+
+	// Find the rectangles for the F face, which is the third face drawn.
+	front := image.Rects[1+2*10 : 1+3*10]
+	if front[0].X != "80" || front[0].Y != "80" || front[0].Fill != "#000000" {
+		t.Errorf("unexpected face rectangle %v", front[0])
+	}
+	expected := []string{"#ff0000", "#ff0000", "#ff0000", "#00ff00",
+		"#808080", "#00ff00", "#00ff00", "#00ff00", "#00ff00"}
+	for i, fill := range expected {
+		if front[i+1].Fill != fill {
+			t.Errorf("sticker %d has fill %s (expected %s)", i, front[i+1].Fill,
+				fill)
+		}
+	}
+	if front[1].X != "82" || front[2].X != "104" || front[4].Y != "104" {
+		t.Errorf("unexpected sticker positions %v", front[1:])
+	}
+}
+
+func TestScrambleSVG(t *testing.T) {
+	scramble, _ := ParseMoves("R U2 F' L D B2")
+	cube := SolvedCubieCube()
+	for _, m := range scramble {
+		cube.Move(m)
+	}
+	if ScrambleSVG(scramble, nil) != cube.SVG(nil) {
+		t.Error("scramble image does not match the scrambled state")
+	}
+}
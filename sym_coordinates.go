@@ -0,0 +1,412 @@
+package gocube
+
+import (
+	"context"
+	"sync"
+)
+
+// udSymmetries lists the 16 symmetries which preserve the U/D axis. These
+// symmetries map the phase-2 move set to itself, and they preserve the phase-1
+// goal of the Y axis cube.
+var udSymmetries = axisSymmetries(1)
+
+// These are the numbers of symmetry classes of each reduced coordinate.
+const (
+	flipSliceClassCount  = 64430
+	cornerPermClassCount = 2768
+	edgePermClassCount   = 2768
+)
+
+// axisSymmetries returns the symmetries which map each of the given faces to
+// the same axis. The identity is always first.
+func axisSymmetries(faces ...int) []Symmetry {
+	var res []Symmetry
+	for s := Symmetry(0); s < SymmetryCount; s++ {
+		preserved := true
+		for _, face := range faces {
+			if f := s.Face(face); f != face && f != oppositeFace(face) {
+				preserved = false
+			}
+		}
+		if preserved {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// A symReduction groups the values of a coordinate into classes which are
+// related by symmetry.
+type symReduction struct {
+	// classes stores class*len(syms) + sym for each raw value, such that
+	// conjugating the raw value by syms[sym] gives the class representative.
+	classes []uint32
+
+	// reps stores the representative of each class.
+	reps []int
+
+	// stabilizers lists the indices of the symmetries other than the identity
+	// which map each representative to itself. Most lists are empty.
+	stabilizers [][]int
+
+	symCount int
+}
+
+// newSymReduction finds the symmetry classes of a coordinate, given a function
+// which conjugates raw values by a symmetry.
+func newSymReduction(size int, syms []Symmetry,
+	conj func(raw int, s Symmetry) int) *symReduction {
+	res := &symReduction{
+		classes:  make([]uint32, size),
+		symCount: len(syms),
+	}
+	assigned := make([]bool, size)
+	for raw := 0; raw < size; raw++ {
+		if assigned[raw] {
+			continue
+		}
+		class := len(res.reps)
+		res.reps = append(res.reps, raw)
+		res.stabilizers = append(res.stabilizers, nil)
+		for i, s := range syms {
+			conjugated := conj(raw, s)
+			if conjugated == raw && i != 0 {
+				res.stabilizers[class] = append(res.stabilizers[class], i)
+			}
+			if assigned[conjugated] {
+				continue
+			}
+			assigned[conjugated] = true
+			inverse := symmetryListIndex(syms, s.Inverse())
+			res.classes[conjugated] = uint32(class*len(syms) + inverse)
+		}
+	}
+	return res
+}
+
+// class returns the class of a raw value, and the index of a symmetry which
+// conjugates the raw value to the class representative.
+func (s *symReduction) class(raw int) (class, sym int) {
+	entry := int(s.classes[raw])
+	return entry / s.symCount, entry % s.symCount
+}
+
+// newConjTable computes the conjugates of every raw value of a coordinate. The
+// result is indexed by raw*len(syms) + sym.
+func newConjTable(size int, syms []Symmetry,
+	conj func(raw int, s Symmetry) int) []uint16 {
+	res := make([]uint16, size*len(syms))
+	for raw := 0; raw < size; raw++ {
+		for i, s := range syms {
+			res[raw*len(syms)+i] = uint16(conj(raw, s))
+		}
+	}
+	return res
+}
+
+func symmetryListIndex(syms []Symmetry, s Symmetry) int {
+	for i, x := range syms {
+		if x == s {
+			return i
+		}
+	}
+	panic("symmetry list is not closed under inverses")
+}
+
+// newSymDistanceTable computes a full-depth distance table over pairs of a
+// symmetry-reduced coordinate and a raw coordinate. An entry is indexed by
+// class*rawSize + raw, where raw has been conjugated by the same symmetry as
+// the first coordinate.
+//
+// The move functions apply a search move from a moveSet, so the distances are
+// measured in the moveSet's metric. The entries are bytes, or nibbles if the
+// nibbles argument is set. Weighted distances which do not fit in a nibble
+// are stored as 14, which is still a lower bound.
+func newSymDistanceTable(reduced *symReduction, reducedMove func(x, m int) int,
+	rawSize int, rawMove func(x, m int) int, rawConj []uint16, moves *moveSet,
+	solvedReduced, solvedRaw int, nibbles bool) distanceTable {
+	symCount := reduced.symCount
+	class, sym := reduced.class(solvedReduced)
+	solved := class*rawSize + int(rawConj[solvedRaw*symCount+sym])
+
+	// The moves of every representative and raw value are computed up front,
+	// since the search applies them many times. For each class and move, this
+	// stores the offset of the new class in the table and the symmetry which
+	// conjugates the result to the representative.
+	moveCount := len(moves.moves)
+	classOffsets := make([]uint32, len(reduced.reps)*moveCount)
+	classSyms := make([]uint8, len(reduced.reps)*moveCount)
+	for class, rep := range reduced.reps {
+		for m := 0; m < moveCount; m++ {
+			newClass, sym := reduced.class(reducedMove(rep, m))
+			classOffsets[class*moveCount+m] = uint32(newClass * rawSize)
+			classSyms[class*moveCount+m] = uint8(sym)
+		}
+	}
+	rawMoves := make([]uint16, rawSize*moveCount)
+	for raw := 0; raw < rawSize; raw++ {
+		for m := 0; m < moveCount; m++ {
+			rawMoves[raw*moveCount+m] = uint16(rawMove(raw, m))
+		}
+	}
+	neighbors := func(idx int, neighbors []int) {
+		class, raw := idx/rawSize, idx%rawSize
+		offsets := classOffsets[class*moveCount : (class+1)*moveCount]
+		syms := classSyms[class*moveCount : (class+1)*moveCount]
+		newRaws := rawMoves[raw*moveCount : (raw+1)*moveCount]
+		for m := range neighbors {
+			conj := rawConj[int(newRaws[m])*symCount+int(syms[m])]
+			neighbors[m] = int(offsets[m]) + int(conj)
+		}
+	}
+
+	size := len(reduced.reps) * rawSize
+	costs := moves.costs()
+	unknown := uint8(0xff)
+	if nibbles {
+		unknown = patternUnknown
+	}
+	index := symIndex{reduced: reduced, rawSize: rawSize, rawConj: rawConj}
+	var res, table distanceTable
+	if nibbles {
+		data := newNibbleArray(size, unknown)
+		res, table = data, &symNibbleTable{nibbleArray: data, symIndex: index}
+	} else {
+		data := newByteTable(size, unknown)
+		res, table = data, &symByteTable{byteTable: data, symIndex: index}
+	}
+	if costs == nil {
+		fillPatternDatabase(table, size, solved, moveCount, neighbors)
+	} else {
+		fillWeightedDistances(context.Background(), table, size, solved, costs,
+			unknown, neighbors)
+	}
+	return res
+}
+
+// searchMoveFunc turns a move table into a function which applies the search
+// moves of a moveSet.
+func searchMoveFunc(moves *moveSet, table func(x, m int) int) func(x, m int) int {
+	return func(x, m int) int {
+		for _, base := range moves.moves[m].moves {
+			x = table(x, base)
+		}
+		return x
+	}
+}
+
+// A symIndex maps states to the entries of a table indexed by a
+// symmetry-reduced coordinate and a raw coordinate.
+//
+// If a class representative is symmetric, a state has one entry for each of
+// the representative's symmetries, so setting one entry must set all of them.
+type symIndex struct {
+	reduced *symReduction
+	rawSize int
+	rawConj []uint16
+}
+
+// equivalent calls f with every other entry for the state of entry i.
+func (s *symIndex) equivalent(i int, f func(j int)) {
+	class := i / s.rawSize
+	stabilizers := s.reduced.stabilizers[class]
+	if len(stabilizers) == 0 {
+		return
+	}
+	raw := i % s.rawSize
+	for _, sym := range stabilizers {
+		f(class*s.rawSize + int(s.rawConj[raw*s.reduced.symCount+sym]))
+	}
+}
+
+// A symByteTable is a byteTable which uses a symIndex.
+type symByteTable struct {
+	byteTable
+	symIndex
+}
+
+func (s *symByteTable) set(i int, value uint8) {
+	s.byteTable[i] = value
+	s.equivalent(i, func(j int) {
+		s.byteTable[j] = value
+	})
+}
+
+// A symNibbleTable is a nibbleArray which uses a symIndex.
+type symNibbleTable struct {
+	nibbleArray
+	symIndex
+}
+
+func (s *symNibbleTable) set(i int, value uint8) {
+	s.nibbleArray.set(i, value)
+	s.equivalent(i, func(j int) {
+		s.nibbleArray.set(j, value)
+	})
+}
+
+// phase1SymTables stores the symmetry data for the phase-1 coordinates.
+//
+// The edge orientation is only preserved by symmetries which preserve the F/B
+// axis, but combined with the positions of the slice edges, it is preserved by
+// all of the udSymmetries. The flip-slice coordinate of a Y axis cube is
+// ESlicePermutation*2048 + FBEdgeOrientation.
+type phase1SymTables struct {
+	flipSlice *symReduction
+	twistConj []uint16
+}
+
+var phase1SymOnce sync.Once
+var phase1SymData *phase1SymTables
+
+// phase1Sym returns the symmetry data for the phase-1 coordinates, computing
+// it the first time it is needed.
+func phase1Sym() *phase1SymTables {
+	phase1SymOnce.Do(func() {
+		syms := udSymmetries
+		phase1SymData = &phase1SymTables{
+			flipSlice: newSymReduction(495*2048, syms,
+				newFlipSliceConjugator(syms)),
+			twistConj: newConjTable(2187, syms, conjugateTwist),
+		}
+	})
+	return phase1SymData
+}
+
+// phase2SymTables stores the symmetry data for the phase-2 coordinates.
+type phase2SymTables struct {
+	corners   *symReduction
+	edges     *symReduction
+	sliceConj []uint16
+}
+
+var phase2SymOnce sync.Once
+var phase2SymData *phase2SymTables
+
+// phase2Sym is like phase1Sym, but for phase-2 coordinates.
+func phase2Sym() *phase2SymTables {
+	phase2SymOnce.Do(func() {
+		syms := udSymmetries
+		phase2SymData = &phase2SymTables{
+			corners:   newSymReduction(40320, syms, conjugateCornerPerm),
+			edges:     newSymReduction(40320, syms, conjugateUDEdgePerm),
+			sliceConj: newConjTable(24, syms, conjugateESlicePerm),
+		}
+	})
+	return phase2SymData
+}
+
+// eSliceChoices maps each E slice coordinate to the slots of the slice edges.
+var eSliceChoices = generateESliceChoices()
+
+func generateESliceChoices() [][4]int {
+	res := make([][4]int, 495)
+	for w := 0; w < 12; w++ {
+		for x := w + 1; x < 12; x++ {
+			for y := x + 1; y < 12; y++ {
+				for z := y + 1; z < 12; z++ {
+					var choice [12]bool
+					choice[w], choice[x], choice[y], choice[z] = true, true,
+						true, true
+					res[encodeChoice(choice[:])] = [4]int{w, x, y, z}
+				}
+			}
+		}
+	}
+	return res
+}
+
+func conjugateTwist(raw int, s Symmetry) int {
+	cube := SolvedCubieCube()
+	cube.Corners = decodeCO(raw)
+	res := cube.Conjugate(s)
+	return encodeCO(&res.Corners)
+}
+
+// conjugateFlipSlice conjugates a flip-slice coordinate, given as
+// slice*2048 + flip.
+func conjugateFlipSlice(raw int, s Symmetry) int {
+	cube := SolvedCubieCube()
+	cube.Edges = decodeEO(raw % 2048)
+	var used [12]bool
+	for i, slot := range eSliceChoices[raw/2048] {
+		cube.Edges[slot].Piece = eSliceSlots[i]
+		used[slot] = true
+	}
+	otherSlots := udEdgeSlots
+	for slot := range cube.Edges {
+		if !used[slot] {
+			cube.Edges[slot].Piece = otherSlots[0]
+			otherSlots = otherSlots[1:]
+		}
+	}
+	res := cube.Conjugate(s)
+	var choice [12]bool
+	for slot, edge := range res.Edges {
+		choice[slot] = edge.Piece == 1 || edge.Piece == 3 || edge.Piece == 7 ||
+			edge.Piece == 9
+	}
+	return encodeChoice(choice[:])*2048 + encodeEO(&res.Edges)
+}
+
+// newFlipSliceConjugator returns a faster version of conjugateFlipSlice for a
+// list of symmetries.
+//
+// A conjugation moves each flip to a new slot, and then flips the edges whose
+// reference sticker changes. This depends only on the slots of the slice
+// edges, so a conjugate is found by combining a table for the flips with a
+// table for the slice.
+func newFlipSliceConjugator(syms []Symmetry) func(raw int,
+	s Symmetry) int {
+	flips := make([]uint16, 2048*len(syms))
+	slices := make([]uint32, 495*len(syms))
+	for i, s := range syms {
+		for slice := 0; slice < 495; slice++ {
+			slices[slice*len(syms)+i] = uint32(conjugateFlipSlice(slice*2048, s))
+		}
+		solvedOffset := int(slices[220*len(syms)+i] % 2048)
+		for flip := 0; flip < 2048; flip++ {
+			conj := conjugateFlipSlice(220*2048+flip, s) % 2048
+			flips[flip*len(syms)+i] = uint16(conj ^ solvedOffset)
+		}
+	}
+	return func(raw int, s Symmetry) int {
+		i := symmetryListIndex(syms, s)
+		return int(slices[raw/2048*len(syms)+i]) ^
+			int(flips[raw%2048*len(syms)+i])
+	}
+}
+
+func conjugateCornerPerm(raw int, s Symmetry) int {
+	cube := SolvedCubieCube()
+	for slot, piece := range decodePermutation(raw, 8) {
+		cube.Corners[slot].Piece = piece
+	}
+	res := cube.Conjugate(s)
+	return encodeYCornerPerm(&res.Corners)
+}
+
+// udEdgeSlots lists the slots of the U and D edges in the order used by
+// encodeUDEdges, and eSliceSlots does the same for encodeESlicePerm.
+var (
+	udEdgeSlots = []int{6, 5, 0, 4, 8, 11, 2, 10}
+	eSliceSlots = []int{1, 3, 7, 9}
+)
+
+func conjugateUDEdgePerm(raw int, s Symmetry) int {
+	cube := SolvedCubieCube()
+	for i, x := range decodePermutation(raw, 8) {
+		cube.Edges[udEdgeSlots[i]].Piece = udEdgeSlots[x]
+	}
+	res := cube.Conjugate(s)
+	return encodeUDEdges(&res.Edges)
+}
+
+func conjugateESlicePerm(raw int, s Symmetry) int {
+	cube := SolvedCubieCube()
+	for i, x := range decodePermutation(raw, 4) {
+		cube.Edges[eSliceSlots[i]].Piece = eSliceSlots[x]
+	}
+	res := cube.Conjugate(s)
+	return encodeESlicePerm(&res.Edges)
+}
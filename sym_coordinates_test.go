@@ -0,0 +1,230 @@
+package gocube
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSymReductionClasses(t *testing.T) {
+	p1 := phase1Sym()
+	p2 := phase2Sym()
+	counts := []struct {
+		name      string
+		reduction *symReduction
+		expected  int
+	}{
+		{"flip-slice", p1.flipSlice, flipSliceClassCount},
+		{"corner perm", p2.corners, cornerPermClassCount},
+		{"edge perm", p2.edges, edgePermClassCount},
+	}
+	for _, c := range counts {
+		if len(c.reduction.reps) != c.expected {
+			t.Errorf("%s: expected %d classes but got %d", c.name, c.expected,
+				len(c.reduction.reps))
+		}
+	}
+
+	// Conjugating a raw value by its symmetry must give its representative.
+	conj := newFlipSliceConjugator(udSymmetries)
+	for raw := 0; raw < 495*2048; raw += 61 {
+		class, sym := p1.flipSlice.class(raw)
+		s := udSymmetries[sym]
+		if conjugateFlipSlice(raw, s) != p1.flipSlice.reps[class] {
+			t.Fatalf("bad class for flip-slice %d", raw)
+		}
+		for _, s := range udSymmetries {
+			if conj(raw, s) != conjugateFlipSlice(raw, s) {
+				t.Fatalf("bad fast conjugate for flip-slice %d", raw)
+			}
+		}
+	}
+	for raw := 0; raw < 40320; raw++ {
+		class, sym := p2.corners.class(raw)
+		if conjugateCornerPerm(raw, udSymmetries[sym]) !=
+			p2.corners.reps[class] {
+			t.Fatalf("bad class for corner perm %d", raw)
+		}
+	}
+}
+
+func TestSymConjugation(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		cube := RandomCubieCube()
+		p1 := cube.Phase1Cube()
+		for _, s := range udSymmetries {
+			conj := cube.Conjugate(s)
+			expected := conj.Phase1Cube()
+			if conjugateTwist(p1.YCornerOrientation, s) !=
+				expected.YCornerOrientation {
+				t.Errorf("bad twist conjugate for symmetry %d", s)
+			}
+			if conjugateFlipSlice(p1.ESlicePermutation*2048+
+				p1.FBEdgeOrientation, s) != expected.ESlicePermutation*2048+
+				expected.FBEdgeOrientation {
+				t.Errorf("bad flip-slice conjugate for symmetry %d", s)
+			}
+		}
+
+		cube = SolvedCubieCube()
+		for j := 0; j < 30; j++ {
+			cube.Move(Phase2Move(rand.Intn(10)).Move(1))
+		}
+		p2, _ := NewPhase2Cube(cube, 1)
+		for _, s := range udSymmetries {
+			expected, err := NewPhase2Cube(cube.Conjugate(s), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conjugateCornerPerm(p2.CornerPermutation, s) !=
+				expected.CornerPermutation {
+				t.Errorf("bad corner conjugate for symmetry %d", s)
+			}
+			if conjugateUDEdgePerm(p2.EdgePermutation, s) !=
+				expected.EdgePermutation {
+				t.Errorf("bad edge conjugate for symmetry %d", s)
+			}
+			if conjugateESlicePerm(p2.SlicePermutation, s) !=
+				expected.SlicePermutation {
+				t.Errorf("bad slice conjugate for symmetry %d", s)
+			}
+		}
+	}
+}
+
+func TestSymDistanceTables(t *testing.T) {
+	p1 := sharedSolverTables(HTM).P1Heuristic
+	p2 := NewPhase2Heuristic(NewPhase2Moves())
+	tables := []struct {
+		name    string
+		data    distanceTable
+		size    int
+		unknown uint8
+	}{
+		{"FlipSliceTwist", nibbleArray(p1.FlipSliceTwist),
+			flipSliceClassCount * 2187, patternUnknown},
+		{"CornersSlice", byteTable(p2.CornersSlice), len(p2.CornersSlice), 0xff},
+		{"EdgesSlice", byteTable(p2.EdgesSlice), len(p2.EdgesSlice), 0xff},
+	}
+	for _, table := range tables {
+		if findEntry(table.data, 0, table.size, table.unknown) != table.size {
+			t.Errorf("%s has unreached entries", table.name)
+		}
+	}
+
+	// The heuristics are exact for the coordinates they track, so they must
+	// agree for cubes which are related by symmetry.
+	for i := 0; i < 100; i++ {
+		cube := RandomCubieCube()
+		s := udSymmetries[rand.Intn(len(udSymmetries))]
+		conj := cube.Conjugate(s)
+		c1, c2 := cube.Phase1Cube(), conj.Phase1Cube()
+		if p1.axisLowerBound(c1.YCornerOrientation, c1.FBEdgeOrientation,
+			c1.ESlicePermutation) != p1.axisLowerBound(c2.YCornerOrientation,
+			c2.FBEdgeOrientation, c2.ESlicePermutation) {
+			t.Errorf("phase-1 bound changed under symmetry %d", s)
+		}
+	}
+
+	// Compare the corner table to a search without symmetry reduction.
+	moves := NewPhase2Moves()
+	raw := newByteTable(40320*24, patternUnknown)
+	fillPatternDatabase(raw, 40320*24, 0, 10, func(idx int, res []int) {
+		for m := range res {
+			res[m] = moves.CornerMoves[idx/24][m]*24 +
+				moves.SliceMoves[idx%24][m]
+		}
+	})
+	for idx, expected := range raw {
+		class, sym := p2.sym.corners.class(idx / 24)
+		slice := p2.sym.sliceConj[(idx%24)*len(udSymmetries)+sym]
+		if actual := p2.CornersSlice[class*24+int(slice)]; actual != expected {
+			t.Fatalf("corners %d: expected %d but got %d", idx, expected, actual)
+		}
+	}
+}
+
+func TestPhase1HeuristicExact(t *testing.T) {
+	p1 := sharedSolverTables(HTM).P1Heuristic
+	moves := sharedSolverTables(HTM).P1Moves
+	bound := func(co, eo, slice int) int {
+		return int(p1.axisLowerBound(co, eo, slice))
+	}
+
+	// A bound is the exact distance if only the solved state has a bound of
+	// 0, and every other state has a neighbor with a smaller bound but none
+	// with a bound smaller by more than one move.
+	if bound(1093, 0, 220) != 0 {
+		t.Fatal("solved state has a nonzero bound")
+	}
+	for i := 0; i < 2000; i++ {
+		co, eo, slice := rand.Intn(2187), rand.Intn(2048), rand.Intn(495)
+		h := bound(co, eo, slice)
+		if h == 0 && (co != 1093 || eo != 0 || slice != 220) {
+			t.Fatalf("unsolved state %d,%d,%d has a bound of 0", co, eo, slice)
+		}
+		closer := h == 0
+		for m := 0; m < 18; m++ {
+			next := bound(moves.COMoves[co][m], moves.EOMoves[eo][m],
+				moves.ESliceMoves[slice][m])
+			if next < h-1 {
+				t.Fatalf("state %d,%d,%d has bound %d but a neighbor has %d",
+					co, eo, slice, h, next)
+			}
+			closer = closer || next == h-1
+		}
+		if !closer {
+			t.Fatalf("state %d,%d,%d has no neighbor closer than %d", co, eo,
+				slice, h)
+		}
+	}
+}
+
+func TestPhase1HeuristicPairwise(t *testing.T) {
+	p1 := sharedSolverTables(HTM).P1Heuristic
+	moves := sharedSolverTables(HTM).P1Moves
+
+	// These are the tables which pairs of the phase-1 coordinates would use,
+	// without symmetry reduction.
+	pairTable := func(size1, size2, solved int, move1, move2 func(x,
+		m int) int) byteTable {
+		res := newByteTable(size1*size2, patternUnknown)
+		fillPatternDatabase(res, len(res), solved, 18, func(idx int,
+			neighbors []int) {
+			for m := range neighbors {
+				neighbors[m] = move1(idx/size2, m)*size2 + move2(idx%size2, m)
+			}
+		})
+		return res
+	}
+	coMove := func(x, m int) int { return moves.COMoves[x][m] }
+	eoMove := func(x, m int) int { return moves.EOMoves[x][m] }
+	sliceMove := func(x, m int) int { return moves.ESliceMoves[x][m] }
+	twistFlip := pairTable(2187, 2048, 1093*2048, coMove, eoMove)
+	sliceFlip := pairTable(495, 2048, 220*2048, sliceMove, eoMove)
+	sliceTwist := pairTable(495, 2187, 220*2187+1093, sliceMove, coMove)
+
+	var better, total int
+	for i := 0; i < 10000; i++ {
+		co, eo, slice := rand.Intn(2187), rand.Intn(2048), rand.Intn(495)
+		pairwise := twistFlip[co*2048+eo]
+		if x := sliceFlip[slice*2048+eo]; x > pairwise {
+			pairwise = x
+		}
+		if x := sliceTwist[slice*2187+co]; x > pairwise {
+			pairwise = x
+		}
+		h := p1.axisLowerBound(co, eo, slice)
+		if h < pairwise {
+			t.Fatalf("state %d,%d,%d has bound %d below pairwise bound %d", co,
+				eo, slice, h, pairwise)
+		}
+		if h > pairwise {
+			better++
+		}
+		total++
+	}
+	t.Logf("bound improved for %d of %d states", better, total)
+	if better*4 < total {
+		t.Errorf("bound only improved for %d of %d states", better, total)
+	}
+}
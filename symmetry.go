@@ -0,0 +1,435 @@
+package gocube
+
+// SymmetryCount is the number of symmetries of the cube.
+const SymmetryCount = 48
+
+// A Symmetry is one of the 48 ways to map the cube onto itself in space: the
+// 24 rotations, and each rotation followed by a reflection.
+//
+// Symmetries 0 through 23 are rotations, and symmetry 0 is the identity.
+// Symmetries 24 through 47 are reflections.
+//
+// A symmetry acts on moves by moving the turned face to a new position. A
+// reflection also reverses the direction of each turn. A symmetry acts on a
+// CubieCube by conjugation, so that the conjugated state is the state which
+// the translated moves would produce.
+type Symmetry int
+
+// RotationSymmetry returns the symmetry which translates moves the same way
+// as Algorithm.Rotate.
+//
+// For example, RotationSymmetry of x maps U to F, since "x U x'" is equivalent
+// to F.
+func RotationSymmetry(r Rotation) Symmetry {
+	o := StandardOrientation()
+	o.Rotate(r)
+	var axes, signs [3]int
+	for axis, pos := range []int{5, 1, 3} {
+		axes[axis], signs[axis] = faceDirection(o[pos-1])
+	}
+	return findSymmetry(axes, signs)
+}
+
+// MirrorSymmetry returns the reflection across the plane perpendicular to an
+// axis (0=x, 1=y, 2=z), which translates moves the same way as
+// Algorithm.Mirror.
+func MirrorSymmetry(axis int) Symmetry {
+	signs := [3]int{1, 1, 1}
+	signs[axis] = -1
+	return findSymmetry([3]int{0, 1, 2}, signs)
+}
+
+// IsReflection returns true if the symmetry is not a rotation.
+func (s Symmetry) IsReflection() bool {
+	return s >= 24
+}
+
+// Inverse returns the symmetry which undoes s.
+func (s Symmetry) Inverse() Symmetry {
+	return symmetryTables[s].inverse
+}
+
+// Multiply returns the symmetry which is equivalent to applying s and then t.
+func (s Symmetry) Multiply(t Symmetry) Symmetry {
+	return symmetryTables[s].products[t]
+}
+
+// Face returns the face (a number from 1 through 6) to which the symmetry
+// maps a given face.
+func (s Symmetry) Face(face int) int {
+	return symmetryTables[s].faces[face-1]
+}
+
+// Move translates a move through the symmetry.
+func (s Symmetry) Move(m Move) Move {
+	turns := m.Turns()
+	if s.IsReflection() && turns != 2 {
+		turns = -turns
+	}
+	return NewMove(s.Face(m.Face()), turns)
+}
+
+// Moves translates a sequence of moves through the symmetry.
+func (s Symmetry) Moves(moves []Move) []Move {
+	res := make([]Move, len(moves))
+	for i, m := range moves {
+		res[i] = s.Move(m)
+	}
+	return res
+}
+
+// Turn translates a turn through the symmetry.
+func (s Symmetry) Turn(t Turn) Turn {
+	turns := t.Turns
+	if s.IsReflection() && turns != 2 {
+		turns = -turns
+	}
+	return NewTurn(t.Kind, s.Face(t.Face), turns)
+}
+
+// Algorithm translates an algorithm through the symmetry.
+func (s Symmetry) Algorithm(a Algorithm) Algorithm {
+	res := make(Algorithm, len(a))
+	for i, t := range a {
+		res[i] = s.Turn(t)
+	}
+	return res
+}
+
+// cornerSlots returns a table which maps each corner slot to the slot the
+// symmetry moves it to.
+func (s Symmetry) cornerSlots() []int {
+	return append([]int{}, symmetryTables[s].corners[:]...)
+}
+
+// edgeSlots is like cornerSlots, but for edges.
+func (s Symmetry) edgeSlots() []int {
+	return append([]int{}, symmetryTables[s].edges[:]...)
+}
+
+// moveTable returns a table which maps each move to its translation.
+func (s Symmetry) moveTable() []Move {
+	res := make([]Move, 18)
+	for m := range res {
+		res[m] = s.Move(Move(m))
+	}
+	return res
+}
+
+// Conjugate returns the state which results from translating every move that
+// produced c through a symmetry.
+func (c *CubieCube) Conjugate(s Symmetry) CubieCube {
+	table := &symmetryTables[s]
+	var res CubieCube
+	for i, corner := range c.Corners {
+		axisMap := cornerAxisMap(corner.Piece, i, corner.Orientation)
+		res.Corners[table.corners[i]] = CubieCorner{
+			Piece:       table.corners[corner.Piece],
+			Orientation: table.axes[axisMap[table.inverseAxes[1]]],
+		}
+	}
+	for i, edge := range c.Edges {
+		slot := table.edges[i]
+		piece := table.edges[edge.Piece]
+		homeAxis := table.inverseAxes[edgeReferenceAxes[piece]]
+		axis := edgeReferenceAxes[i]
+		if (homeAxis == edgeReferenceAxes[edge.Piece]) == edge.Flip {
+			axis = edgeOtherAxes[i]
+		}
+		res.Edges[slot] = CubieEdge{
+			Piece: piece,
+			Flip:  table.axes[axis] != edgeReferenceAxes[slot],
+		}
+	}
+	return res
+}
+
+// Canonical returns the smallest state which is equivalent to c under
+// symmetry, along with a symmetry which conjugates c to that state.
+//
+// Two states have the same canonical representative if and only if they are
+// related by a symmetry.
+func (c *CubieCube) Canonical() (CubieCube, Symmetry) {
+	best := *c
+	var bestSym Symmetry
+	for s := Symmetry(1); s < SymmetryCount; s++ {
+		if conj := c.Conjugate(s); conj.less(&best) {
+			best = conj
+			bestSym = s
+		}
+	}
+	return best, bestSym
+}
+
+// SymmetryClass returns the distinct states which are equivalent to c under
+// symmetry, including c itself.
+func (c *CubieCube) SymmetryClass() []CubieCube {
+	seen := map[CubieCube]bool{}
+	var res []CubieCube
+	for s := Symmetry(0); s < SymmetryCount; s++ {
+		conj := c.Conjugate(s)
+		if !seen[conj] {
+			seen[conj] = true
+			res = append(res, conj)
+		}
+	}
+	return res
+}
+
+// Symmetries returns the symmetries which leave c unchanged. The identity is
+// always included.
+func (c *CubieCube) Symmetries() []Symmetry {
+	var res []Symmetry
+	for s := Symmetry(0); s < SymmetryCount; s++ {
+		if c.Conjugate(s) == *c {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// less orders states lexicographically by their corners and then their edges.
+func (c *CubieCube) less(d *CubieCube) bool {
+	for i, corner := range c.Corners {
+		other := d.Corners[i]
+		if corner.Piece != other.Piece {
+			return corner.Piece < other.Piece
+		} else if corner.Orientation != other.Orientation {
+			return corner.Orientation < other.Orientation
+		}
+	}
+	for i, edge := range c.Edges {
+		other := d.Edges[i]
+		if edge.Piece != other.Piece {
+			return edge.Piece < other.Piece
+		} else if edge.Flip != other.Flip {
+			return other.Flip
+		}
+	}
+	return false
+}
+
+type symmetryTable struct {
+	// axes maps each axis to the axis it is moved to, and signs indicates if
+	// the positive end of each axis is moved to the negative end.
+	axes        [3]int
+	signs       [3]int
+	inverseAxes [3]int
+
+	faces   [6]int
+	corners [8]int
+	edges   [12]int
+
+	inverse  Symmetry
+	products [SymmetryCount]Symmetry
+}
+
+var symmetryTables = generateSymmetryTables()
+
+func generateSymmetryTables() []symmetryTable {
+	var rotations, reflections []symmetryTable
+	for _, axes := range axisPermutations {
+		for signMask := 0; signMask < 8; signMask++ {
+			var table symmetryTable
+			table.axes = axes
+			det := permutationSign(axes)
+			for axis := 0; axis < 3; axis++ {
+				table.inverseAxes[axes[axis]] = axis
+				table.signs[axis] = 1
+				if signMask&(1<<uint(axis)) != 0 {
+					table.signs[axis] = -1
+					det = -det
+				}
+			}
+			if det > 0 {
+				rotations = append(rotations, table)
+			} else {
+				reflections = append(reflections, table)
+			}
+		}
+	}
+	res := append(rotations, reflections...)
+
+	for i := range res {
+		table := &res[i]
+		for face := 1; face <= 6; face++ {
+			axis, sign := faceDirection(face)
+			table.faces[face-1] = directionFace(table.axes[axis],
+				sign*table.signs[axis])
+		}
+		for slot := 0; slot < 8; slot++ {
+			table.corners[slot] = cornerSlot(table.transform(cornerPosition(slot)))
+		}
+		for slot := 0; slot < 12; slot++ {
+			table.edges[slot] = edgeSlot(table.transform(edgePosition(slot)))
+		}
+	}
+
+	for i := range res {
+		for j := range res {
+			var axes, signs [3]int
+			for axis := 0; axis < 3; axis++ {
+				middle := res[i].axes[axis]
+				axes[axis] = res[j].axes[middle]
+				signs[axis] = res[i].signs[axis] * res[j].signs[middle]
+			}
+			product := symmetryIndex(res, axes, signs)
+			res[i].products[j] = product
+			if product == 0 {
+				res[i].inverse = Symmetry(j)
+			}
+		}
+	}
+
+	return res
+}
+
+// transform applies the symmetry to a position vector.
+func (s *symmetryTable) transform(v [3]int) [3]int {
+	var res [3]int
+	for axis, x := range v {
+		res[s.axes[axis]] = x * s.signs[axis]
+	}
+	return res
+}
+
+var axisPermutations = [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0},
+	{2, 0, 1}, {2, 1, 0}}
+
+func permutationSign(p [3]int) int {
+	sign := 1
+	for i := 0; i < 3; i++ {
+		for j := i + 1; j < 3; j++ {
+			if p[i] > p[j] {
+				sign = -sign
+			}
+		}
+	}
+	return sign
+}
+
+func findSymmetry(axes, signs [3]int) Symmetry {
+	return symmetryIndex(symmetryTables, axes, signs)
+}
+
+func symmetryIndex(tables []symmetryTable, axes, signs [3]int) Symmetry {
+	for i, table := range tables {
+		if table.axes == axes && table.signs == signs {
+			return Symmetry(i)
+		}
+	}
+	panic("invalid symmetry")
+}
+
+// faceDirection returns the axis and sign of the outward normal of a face.
+func faceDirection(face int) (axis, sign int) {
+	axis = []int{1, 1, 2, 2, 0, 0}[face-1]
+	if face%2 == 1 {
+		return axis, 1
+	}
+	return axis, -1
+}
+
+func directionFace(axis, sign int) int {
+	face := []int{5, 1, 3}[axis]
+	if sign < 0 {
+		return oppositeFace(face)
+	}
+	return face
+}
+
+func cornerPosition(slot int) [3]int {
+	var res [3]int
+	for axis := 0; axis < 3; axis++ {
+		res[axis] = -1
+		if slot&(1<<uint(axis)) != 0 {
+			res[axis] = 1
+		}
+	}
+	return res
+}
+
+func cornerSlot(position [3]int) int {
+	var res int
+	for axis, x := range position {
+		if x > 0 {
+			res |= 1 << uint(axis)
+		}
+	}
+	return res
+}
+
+func edgePosition(slot int) [3]int {
+	var res [3]int
+	for _, letter := range EdgeNames[slot] {
+		axis, sign := faceDirection(faceNumber(letter))
+		res[axis] = sign
+	}
+	return res
+}
+
+func edgeSlot(position [3]int) int {
+	for slot := 0; slot < 12; slot++ {
+		if edgePosition(slot) == position {
+			return slot
+		}
+	}
+	panic("invalid edge position")
+}
+
+// cornerAxisMap finds the rotation which takes a corner from its home slot to
+// a given slot and orientation. The result maps each axis to the axis it is
+// moved to.
+func cornerAxisMap(home, slot, orientation int) [3]int {
+	// The rotation must preserve the handedness of the corner, so the sign of
+	// the axis permutation depends on whether the slots have the same parity.
+	sign := 1
+	if isOddCornerSlot(home) != isOddCornerSlot(slot) {
+		sign = -1
+	}
+	for _, p := range axisPermutations {
+		if p[1] == orientation && permutationSign(p) == sign {
+			return p
+		}
+	}
+	panic("invalid corner orientation")
+}
+
+// edgeReferenceAxes and edgeOtherAxes cache edgeReferenceAxis and
+// edgeOtherAxis for each slot, since Conjugate is used to build move tables.
+var edgeReferenceAxes, edgeOtherAxes = edgeAxisTables()
+
+func edgeAxisTables() (reference, other [12]int) {
+	for slot := 0; slot < 12; slot++ {
+		reference[slot] = edgeReferenceAxis(slot)
+		other[slot] = edgeOtherAxis(slot)
+	}
+	return
+}
+
+// edgeReferenceAxis returns the axis of the sticker which determines whether
+// an edge in a slot is flipped: the U/D sticker if there is one, or else the
+// F/B sticker.
+func edgeReferenceAxis(slot int) int {
+	if edgePosition(slot)[1] != 0 {
+		return 1
+	}
+	return 2
+}
+
+// edgeOtherAxis returns the axis of the sticker of a slot which is not along
+// the reference axis.
+func edgeOtherAxis(slot int) int {
+	position := edgePosition(slot)
+	reference := edgeReferenceAxis(slot)
+	for axis, x := range position {
+		if x != 0 && axis != reference {
+			return axis
+		}
+	}
+	panic("invalid edge slot")
+}
+
+func faceNumber(letter rune) int {
+	return map[rune]int{'U': 1, 'D': 2, 'F': 3, 'B': 4, 'R': 5, 'L': 6}[letter]
+}
@@ -0,0 +1,115 @@
+package gocube
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSymmetryGroup(t *testing.T) {
+	if Symmetry(0).Multiply(5) != 5 || Symmetry(5).Multiply(0) != 5 {
+		t.Error("symmetry 0 is not the identity")
+	}
+	for s := Symmetry(0); s < SymmetryCount; s++ {
+		if s.Multiply(s.Inverse()) != 0 || s.Inverse().Multiply(s) != 0 {
+			t.Errorf("bad inverse for %d", s)
+		}
+		for u := Symmetry(0); u < SymmetryCount; u++ {
+			if s.Multiply(u).IsReflection() != (s.IsReflection() !=
+				u.IsReflection()) {
+				t.Errorf("bad product for %d and %d", s, u)
+			}
+		}
+	}
+}
+
+func TestSymmetryAlgorithm(t *testing.T) {
+	alg, _ := ParseAlgorithm("R U' F2 Dw M' B L2 x S E2 z' Lw")
+	for r := Rotation(0); r < 9; r++ {
+		s := RotationSymmetry(r)
+		if s.IsReflection() {
+			t.Errorf("rotation %s gave reflection", r)
+		}
+		if actual, expected := s.Algorithm(alg).String(),
+			alg.Rotate(r).String(); actual != expected {
+			t.Errorf("rotation %s gave %s (expected %s)", r, actual, expected)
+		}
+	}
+	for axis := 0; axis < 3; axis++ {
+		s := MirrorSymmetry(axis)
+		if !s.IsReflection() {
+			t.Errorf("mirror %d is not a reflection", axis)
+		}
+		if actual, expected := s.Algorithm(alg).String(),
+			alg.Mirror(axis).String(); actual != expected {
+			t.Errorf("mirror %d gave %s (expected %s)", axis, actual, expected)
+		}
+	}
+}
+
+func TestSymmetryConjugate(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		moves := make([]Move, 30)
+		cube := SolvedCubieCube()
+		for j := range moves {
+			moves[j] = Move(rand.Intn(18))
+			cube.Move(moves[j])
+		}
+		for s := Symmetry(0); s < SymmetryCount; s++ {
+			expected := SolvedCubieCube()
+			for _, m := range s.Moves(moves) {
+				expected.Move(m)
+			}
+			actual := cube.Conjugate(s)
+			if actual != expected {
+				t.Errorf("symmetry %d: unexpected conjugate", s)
+			}
+			if actual.Validate() != nil {
+				t.Errorf("symmetry %d: invalid conjugate", s)
+			}
+			for _, u := range []Symmetry{3, 17, 30, 47} {
+				double := actual.Conjugate(u)
+				if double != cube.Conjugate(s.Multiply(u)) {
+					t.Errorf("bad product for symmetries %d and %d", s, u)
+				}
+			}
+		}
+	}
+}
+
+func TestSymmetryCanonical(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		canonical, s := cube.Canonical()
+		if cube.Conjugate(s) != canonical {
+			t.Error("bad canonical symmetry")
+		}
+		conj := cube.Conjugate(Symmetry(rand.Intn(SymmetryCount)))
+		if other, _ := conj.Canonical(); other != canonical {
+			t.Error("canonical representative depends on symmetry")
+		}
+		if len(cube.SymmetryClass())*len(cube.Symmetries()) != SymmetryCount {
+			t.Error("unexpected class size")
+		}
+	}
+}
+
+func TestSymmetrySymmetries(t *testing.T) {
+	cases := map[string]int{
+		"":   48,
+		"U":  4,
+		"U2": 8,
+		"U R2 F B R B2 R U2 L B2 R U' D' R2 F R' L B2 U2 F2": 48,
+		"U2 D2 F2 B2 R2 L2": 48,
+		"R U R' U'":         1,
+	}
+	for s, count := range cases {
+		alg, _ := ParseAlgorithm(s)
+		cube, _ := applyAlgorithm(t, alg.String())
+		if actual := len(cube.Symmetries()); actual != count {
+			t.Errorf("%q has %d symmetries (expected %d)", s, actual, count)
+		}
+		if len(cube.SymmetryClass()) != SymmetryCount/count {
+			t.Errorf("%q has unexpected class size", s)
+		}
+	}
+}
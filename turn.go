@@ -0,0 +1,171 @@
+package gocube
+
+// A TurnKind indicates which layers of the cube a Turn affects.
+type TurnKind int
+
+const (
+	// FaceTurn turns a single outer layer, like R.
+	FaceTurn TurnKind = iota
+
+	// WideTurn turns an outer layer along with the adjacent middle layer, like
+	// Rw or r.
+	WideTurn
+
+	// SliceTurn turns a middle layer, like M.
+	SliceTurn
+
+	// RotationTurn turns the entire cube, like x.
+	RotationTurn
+)
+
+// A Turn is a single step of an algorithm. Unlike a Move, a Turn may affect
+// the middle layers of the cube or rotate the whole cube.
+//
+// The Face field is a number from 1 through 6 indicating U, D, F, B, R and L
+// respectively, just like Move.Face(). The turn goes in the direction of a
+// clockwise turn of that face. Slice turns always use the face that their
+// notation follows: L for M, D for E, and F for S. Rotations always use the
+// face that their notation follows: R for x, U for y, and F for z.
+//
+// The Turns field is 1, -1, or 2.
+type Turn struct {
+	Kind  TurnKind
+	Face  int
+	Turns int
+}
+
+// NewTurn creates a Turn of a given kind which follows the direction of a
+// face. The face is a number in the range [1, 6] and turns is 1, -1, or 2.
+//
+// Slices and rotations which follow the opposite face of their usual face are
+// converted to their usual face. For example, a slice following the R face is
+// converted to an M turn in the opposite direction.
+func NewTurn(kind TurnKind, face, turns int) Turn {
+	var usual int
+	switch kind {
+	case SliceTurn:
+		usual = []int{2, 2, 3, 3, 6, 6}[face-1]
+	case RotationTurn:
+		usual = []int{1, 1, 3, 3, 5, 5}[face-1]
+	default:
+		return Turn{kind, face, turns}
+	}
+	if face != usual && turns != 2 {
+		turns = -turns
+	}
+	return Turn{kind, usual, turns}
+}
+
+// MoveTurn creates a face Turn which is equivalent to a Move.
+func MoveTurn(m Move) Turn {
+	return Turn{FaceTurn, m.Face(), m.Turns()}
+}
+
+// Inverse returns the turn's inverse.
+func (t Turn) Inverse() Turn {
+	if t.Turns == 2 {
+		return t
+	}
+	return Turn{t.Kind, t.Face, -t.Turns}
+}
+
+// String converts the turn to a WCA-notation string.
+func (t Turn) String() string {
+	var res string
+	switch t.Kind {
+	case FaceTurn:
+		res = faceName(t.Face)
+	case WideTurn:
+		res = faceName(t.Face) + "w"
+	case SliceTurn:
+		res = map[int]string{6: "M", 2: "E", 3: "S"}[t.Face]
+	case RotationTurn:
+		res = map[int]string{5: "x", 1: "y", 3: "z"}[t.Face]
+	}
+	if t.Turns == -1 {
+		return res + "'"
+	} else if t.Turns == 2 {
+		return res + "2"
+	}
+	return res
+}
+
+// Rotation returns the rotation of the cube's centers caused by the turn, if
+// there is one.
+//
+// Face turns do not rotate the centers, while slices, wide turns and rotations
+// do.
+func (t Turn) Rotation() (Rotation, bool) {
+	if t.Kind == FaceTurn {
+		return 0, false
+	}
+	turns := t.Turns
+	if (t.Face == 2 || t.Face == 4 || t.Face == 6) && turns != 2 {
+		turns = -turns
+	}
+	return NewRotation(t.Axis(), turns), true
+}
+
+// Axis returns the axis (0=x, 1=y, 2=z) around which the turn rotates.
+//
+// Turns around the same axis commute with each other.
+func (t Turn) Axis() int {
+	return []int{1, 1, 2, 2, 0, 0}[t.Face-1]
+}
+
+// Mirror reflects the turn across the plane perpendicular to an axis (0=x,
+// 1=y, 2=z). The x axis corresponds to the M plane, the y axis to the E plane,
+// and the z axis to the S plane.
+//
+// For example, mirroring R across the M plane gives L'.
+func (t Turn) Mirror(axis int) Turn {
+	face := t.Face
+	if t.Axis() == axis {
+		face = oppositeFace(face)
+	}
+	return NewTurn(t.Kind, face, t.Inverse().Turns)
+}
+
+// QuarterTurns returns the number of quarter turns of a single layer which
+// the turn involves, ignoring rotations.
+//
+// Face and wide turns count as one layer, while slices count as two.
+func (t Turn) QuarterTurns() int {
+	var layers int
+	switch t.Kind {
+	case FaceTurn, WideTurn:
+		layers = 1
+	case SliceTurn:
+		layers = 2
+	}
+	if t.Turns == 2 {
+		return layers * 2
+	}
+	return layers
+}
+
+// Moves returns the face turns which, when followed by t.Rotation(), are
+// equivalent to the turn.
+func (t Turn) Moves() []Move {
+	switch t.Kind {
+	case FaceTurn:
+		return []Move{NewMove(t.Face, t.Turns)}
+	case WideTurn:
+		return []Move{NewMove(oppositeFace(t.Face), t.Turns)}
+	case SliceTurn:
+		inverse := t.Turns
+		if inverse != 2 {
+			inverse = -inverse
+		}
+		return []Move{NewMove(t.Face, inverse),
+			NewMove(oppositeFace(t.Face), t.Turns)}
+	}
+	return nil
+}
+
+func oppositeFace(face int) int {
+	if face%2 == 1 {
+		return face + 1
+	}
+	return face - 1
+}
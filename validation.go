@@ -0,0 +1,270 @@
+package gocube
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A PieceKind distinguishes corner pieces from edge pieces.
+type PieceKind int
+
+const (
+	CornerKind PieceKind = iota
+	EdgeKind
+)
+
+// String returns "corner" or "edge".
+func (p PieceKind) String() string {
+	if p == CornerKind {
+		return "corner"
+	}
+	return "edge"
+}
+
+// SlotName returns the name of a slot, such as "UFR" or "UF".
+func (p PieceKind) SlotName(slot int) string {
+	names := CornerNames
+	if p == EdgeKind {
+		names = EdgeNames
+	}
+	if slot < 0 || slot >= len(names) {
+		return strconv.Itoa(slot)
+	}
+	return names[slot]
+}
+
+// A CenterError indicates that the center of a face has an unexpected color.
+type CenterError struct {
+	// Face is the face number, from 1 to 6.
+	Face int
+
+	// Color is the color which was found on the center.
+	Color int
+}
+
+func (c *CenterError) Error() string {
+	return fmt.Sprintf("center of %s face is %s but should be %s",
+		faceName(c.Face), stickerLetters([]int{c.Color}),
+		stickerLetters([]int{c.Face}))
+}
+
+// A StickerError indicates that the stickers in a slot do not belong to any
+// piece.
+type StickerError struct {
+	Kind PieceKind
+	Slot int
+
+	// Stickers lists the colors in the order of the slot's name.
+	Stickers []int
+}
+
+func (s *StickerError) Error() string {
+	return fmt.Sprintf("impossible %s stickers %s at %s", s.Kind,
+		stickerLetters(s.Stickers), s.Kind.SlotName(s.Slot))
+}
+
+// An InvalidCubieError indicates that a slot contains an out-of-range piece or
+// orientation.
+type InvalidCubieError struct {
+	Kind PieceKind
+	Slot int
+}
+
+func (i *InvalidCubieError) Error() string {
+	return fmt.Sprintf("%s slot %s contains an invalid piece", i.Kind,
+		i.Kind.SlotName(i.Slot))
+}
+
+// A DuplicatePieceError indicates that a piece occurs in more than one slot.
+type DuplicatePieceError struct {
+	Kind  PieceKind
+	Piece int
+	Slots []int
+}
+
+func (d *DuplicatePieceError) Error() string {
+	return fmt.Sprintf("%s %s appears in multiple slots: %s", d.Kind,
+		d.Kind.SlotName(d.Piece), slotNames(d.Kind, d.Slots))
+}
+
+// A MissingPieceError indicates that a piece does not occur in any slot.
+type MissingPieceError struct {
+	Kind  PieceKind
+	Piece int
+}
+
+func (m *MissingPieceError) Error() string {
+	return fmt.Sprintf("%s %s is missing", m.Kind, m.Kind.SlotName(m.Piece))
+}
+
+// A TwistError indicates that the corner twists do not add up to a multiple of
+// three.
+type TwistError struct {
+	// Twist is the sum of the clockwise corner twists, modulo 3.
+	Twist int
+
+	// Slots lists the corners which are twisted.
+	Slots []int
+}
+
+func (t *TwistError) Error() string {
+	return fmt.Sprintf("corner twists add up to %d (twisted corners: %s)",
+		t.Twist, slotNames(CornerKind, t.Slots))
+}
+
+// A FlipError indicates that an odd number of edges are flipped.
+type FlipError struct {
+	// Slots lists the edges which are flipped.
+	Slots []int
+}
+
+func (f *FlipError) Error() string {
+	return "an odd number of edges are flipped (flipped edges: " +
+		slotNames(EdgeKind, f.Slots) + ")"
+}
+
+// A ParityError indicates that the parity of the corner permutation does not
+// match the parity of the edge permutation.
+type ParityError struct {
+	// CornerParity is true if the corner permutation is even.
+	CornerParity bool
+
+	// EdgeParity is true if the edge permutation is even.
+	EdgeParity bool
+}
+
+func (p *ParityError) Error() string {
+	parityName := map[bool]string{true: "even", false: "odd"}
+	return "corner permutation is " + parityName[p.CornerParity] +
+		" but edge permutation is " + parityName[p.EdgeParity]
+}
+
+// Validate checks that the cube could be reached from the solved state by
+// turning faces.
+//
+// The returned error, if any, is a *InvalidCubieError, *DuplicatePieceError,
+// *MissingPieceError, *TwistError, *FlipError, or *ParityError.
+func (c *CubieCube) Validate() error {
+	var cornerSlots [8][]int
+	for i, corner := range c.Corners {
+		if corner.Piece < 0 || corner.Piece >= 8 || corner.Orientation < 0 ||
+			corner.Orientation > 2 {
+			return &InvalidCubieError{CornerKind, i}
+		}
+		cornerSlots[corner.Piece] = append(cornerSlots[corner.Piece], i)
+	}
+	var edgeSlots [12][]int
+	for i, edge := range c.Edges {
+		if edge.Piece < 0 || edge.Piece >= 12 {
+			return &InvalidCubieError{EdgeKind, i}
+		}
+		edgeSlots[edge.Piece] = append(edgeSlots[edge.Piece], i)
+	}
+
+	for piece, slots := range cornerSlots {
+		if len(slots) > 1 {
+			return &DuplicatePieceError{CornerKind, piece, slots}
+		}
+	}
+	for piece, slots := range edgeSlots {
+		if len(slots) > 1 {
+			return &DuplicatePieceError{EdgeKind, piece, slots}
+		}
+	}
+	for piece, slots := range cornerSlots {
+		if len(slots) == 0 {
+			return &MissingPieceError{CornerKind, piece}
+		}
+	}
+	for piece, slots := range edgeSlots {
+		if len(slots) == 0 {
+			return &MissingPieceError{EdgeKind, piece}
+		}
+	}
+
+	var twisted []int
+	var twist int
+	for i := 0; i < 8; i++ {
+		if t := c.Corners.Twist(i); t != 0 {
+			twisted = append(twisted, i)
+			twist += t
+		}
+	}
+	if twist%3 != 0 {
+		return &TwistError{twist % 3, twisted}
+	}
+
+	var flipped []int
+	for i, edge := range c.Edges {
+		if edge.Flip {
+			flipped = append(flipped, i)
+		}
+	}
+	if len(flipped)%2 != 0 {
+		return &FlipError{flipped}
+	}
+
+	cornerParity := c.Corners.Parity()
+	edgeParity := c.Edges.Parity()
+	if cornerParity != edgeParity {
+		return &ParityError{cornerParity, edgeParity}
+	}
+
+	return nil
+}
+
+// Validate checks that the stickers describe a cube in the standard color
+// scheme which could be reached from the solved state by turning faces.
+//
+// In addition to the errors returned by CubieCube.Validate, this may return a
+// *CenterError or a *StickerError.
+func (s *StickerCube) Validate() error {
+	for face := 1; face <= 6; face++ {
+		if color := s[(face-1)*9+4]; color != face {
+			return &CenterError{face, color}
+		}
+	}
+	cubie, err := s.CubieCube()
+	if err != nil {
+		return err
+	}
+	return cubie.Validate()
+}
+
+func faceName(face int) string {
+	if face < 1 || face > 6 {
+		return strconv.Itoa(face)
+	}
+	return string("UDFBRL"[face-1])
+}
+
+func slotNames(kind PieceKind, slots []int) string {
+	names := make([]string, len(slots))
+	for i, slot := range slots {
+		names[i] = kind.SlotName(slot)
+	}
+	return strings.Join(names, ", ")
+}
+
+func stickerLetters(stickers []int) string {
+	var res string
+	for _, sticker := range stickers {
+		if sticker < 1 || sticker > 6 {
+			res += "?"
+		} else {
+			res += string(" WYGBRO"[sticker])
+		}
+	}
+	return res
+}
+
+// A CenterTwistError indicates that the center twists of a SuperCube are out
+// of range or that their total does not match the parity of the permutation.
+type CenterTwistError struct {
+	Centers [6]int
+}
+
+func (c *CenterTwistError) Error() string {
+	return fmt.Sprintf("impossible center twists %v", c.Centers)
+}
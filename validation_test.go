@@ -0,0 +1,102 @@
+package gocube
+
+import "testing"
+
+func TestCubieCubeValidate(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		cube := RandomCubieCube()
+		if err := cube.Validate(); err != nil {
+			t.Fatal("unexpected error for valid cube:", err)
+		}
+	}
+
+	cube := SolvedCubieCube()
+	cube.Corners[0] = cube.Corners[1]
+	if err, ok := cube.Validate().(*DuplicatePieceError); !ok {
+		t.Error("expected duplicate piece error but got", cube.Validate())
+	} else if err.Kind != CornerKind || err.Piece != 1 || len(err.Slots) != 2 {
+		t.Error("unexpected error:", err)
+	} else if err.Error() != "corner DBR appears in multiple slots: DLB, DBR" {
+		t.Error("unexpected message:", err)
+	}
+
+	cube = SolvedCubieCube()
+	cube.Edges[3].Piece = 12
+	if _, ok := cube.Validate().(*InvalidCubieError); !ok {
+		t.Error("expected invalid cubie error but got", cube.Validate())
+	}
+
+	cube = SolvedCubieCube()
+	cube.Corners[7].Orientation = 0
+	if err, ok := cube.Validate().(*TwistError); !ok {
+		t.Error("expected twist error but got", cube.Validate())
+	} else if err.Twist != 1 || len(err.Slots) != 1 || err.Slots[0] != 7 {
+		t.Error("unexpected error:", err)
+	}
+
+	cube = SolvedCubieCube()
+	cube.Edges[5].Flip = true
+	if err, ok := cube.Validate().(*FlipError); !ok {
+		t.Error("expected flip error but got", cube.Validate())
+	} else if err.Error() != "an odd number of edges are flipped "+
+		"(flipped edges: UR)" {
+		t.Error("unexpected message:", err)
+	}
+
+	cube = SolvedCubieCube()
+	cube.Edges[0], cube.Edges[1] = cube.Edges[1], cube.Edges[0]
+	if err, ok := cube.Validate().(*ParityError); !ok {
+		t.Error("expected parity error but got", cube.Validate())
+	} else if !err.CornerParity || err.EdgeParity {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestStickerCubeValidate(t *testing.T) {
+	stickers := SolvedStickerCube()
+	if err := stickers.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the U sticker of the UFR corner to green.
+	stickers[8] = 3
+	if err, ok := stickers.Validate().(*StickerError); !ok {
+		t.Error("expected sticker error but got", stickers.Validate())
+	} else if err.Error() != "impossible corner stickers GGR at UFR" {
+		t.Error("unexpected message:", err)
+	}
+
+	// Swap two stickers on the UFR corner to mirror it.
+	stickers = SolvedStickerCube()
+	stickers[20], stickers[36] = stickers[36], stickers[20]
+	if err, ok := stickers.Validate().(*StickerError); !ok {
+		t.Error("expected sticker error but got", stickers.Validate())
+	} else if err.Kind != CornerKind || err.Slot != 7 {
+		t.Error("unexpected error:", err)
+	}
+
+	// Give the UF edge two white stickers.
+	stickers = SolvedStickerCube()
+	stickers[19] = 1
+	if err, ok := stickers.Validate().(*StickerError); !ok {
+		t.Error("expected sticker error but got", stickers.Validate())
+	} else if err.Error() != "impossible edge stickers WW at UF" {
+		t.Error("unexpected message:", err)
+	}
+
+	stickers = SolvedStickerCube()
+	stickers[4] = 2
+	if err, ok := stickers.Validate().(*CenterError); !ok {
+		t.Error("expected center error but got", stickers.Validate())
+	} else if err.Face != 1 || err.Color != 2 {
+		t.Error("unexpected error:", err)
+	}
+
+	// Swapping two edges with their stickers results in a parity error.
+	stickers = SolvedStickerCube()
+	stickers[7], stickers[19], stickers[5], stickers[37] =
+		stickers[5], stickers[37], stickers[7], stickers[19]
+	if _, ok := stickers.Validate().(*ParityError); !ok {
+		t.Error("expected parity error but got", stickers.Validate())
+	}
+}